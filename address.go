@@ -0,0 +1,37 @@
+package goexch
+
+import (
+	"regexp"
+	"strings"
+)
+
+// addressFormats holds a loose format check per currency. It only catches
+// addresses that are obviously wrong and does not verify checksums.
+var addressFormats = map[CryptoCurrency]*regexp.Regexp{
+	Bitcoin:          regexp.MustCompile(`^([13][1-9A-HJ-NP-Za-km-z]{25,34}|bc1[02-9ac-hj-np-z]{11,71})$`),
+	BitcoinLightning: regexp.MustCompile(`^ln(bc|tb)[0-9a-z]+$`),
+	Litecoin:         regexp.MustCompile(`^([LM3][1-9A-HJ-NP-Za-km-z]{25,34}|ltc1[02-9ac-hj-np-z]{11,71})$`),
+	Dash:             regexp.MustCompile(`^[X7][1-9A-HJ-NP-Za-km-z]{33}$`),
+	Monero:           regexp.MustCompile(`^[48][1-9A-HJ-NP-Za-km-z]{94}([1-9A-HJ-NP-Za-km-z]{11})?$`),
+	Ethereum:         regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`),
+	USDCoinErc20:     regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`),
+	TetherErc20:      regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`),
+	Dai:              regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`),
+}
+
+// ValidAddress reports whether address looks like a valid address for the
+// currency. Currencies without a known format are always accepted.
+func ValidAddress(c CryptoCurrency, address string) bool {
+	format, ok := addressFormats[c]
+	if !ok {
+		return true
+	}
+
+	// Lightning invoices and bech32 addresses are case-insensitive
+	lower := strings.ToLower(address)
+	if c == BitcoinLightning || strings.HasPrefix(lower, "bc1") || strings.HasPrefix(lower, "ltc1") {
+		address = lower
+	}
+
+	return format.MatchString(address)
+}
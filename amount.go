@@ -0,0 +1,148 @@
+package goexch
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// scale returns 10^decimals as a rational.
+func scale(decimals int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+}
+
+// ValidatePrecision returns an error if amount has more decimal places than
+// the currency supports. Unknown currencies are not checked.
+func ValidatePrecision(amount *big.Rat, c CryptoCurrency) error {
+	info, ok := c.Info()
+	if !ok || amount == nil {
+		return nil
+	}
+
+	scaled := new(big.Rat).Mul(amount, scale(info.Decimals))
+	if !scaled.IsInt() {
+		return fmt.Errorf("amount has more than the %d decimals supported by %s", info.Decimals, c)
+	}
+
+	return nil
+}
+
+// RoundingMode selects how amounts are rounded to a currency's precision.
+type RoundingMode int
+
+const (
+	// RoundDown truncates towards zero, so an amount never implies more than
+	// will be received. It is the default.
+	RoundDown RoundingMode = iota
+	// RoundToNearest rounds to the nearest value, halves away from zero.
+	RoundToNearest
+	// RoundUp rounds away from zero.
+	RoundUp
+)
+
+// RoundToPrecision truncates amount to the number of decimal places the
+// currency supports, never rounding up. Unknown currencies are returned as is.
+func RoundToPrecision(amount *big.Rat, c CryptoCurrency) *big.Rat {
+	return RoundAmount(amount, c, RoundDown)
+}
+
+// RoundAmount is like RoundToPrecision but rounds per mode.
+func RoundAmount(amount *big.Rat, c CryptoCurrency, mode RoundingMode) *big.Rat {
+	info, ok := c.Info()
+	if !ok || amount == nil {
+		return amount
+	}
+	return round(amount, info.Decimals, mode)
+}
+
+// round rounds amount to decimals places per mode.
+func round(amount *big.Rat, decimals int, mode RoundingMode) *big.Rat {
+	factor := scale(decimals)
+	scaled := new(big.Rat).Mul(amount, factor)
+	rounded, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	if rem.Sign() != 0 {
+		away := false
+		switch mode {
+		case RoundUp:
+			away = true
+		case RoundToNearest:
+			// |rem| * 2 >= denom
+			twice := new(big.Int).Abs(rem)
+			away = twice.Lsh(twice, 1).Cmp(scaled.Denom()) >= 0
+		}
+		if away {
+			rounded.Add(rounded, big.NewInt(int64(scaled.Sign())))
+		}
+	}
+
+	return new(big.Rat).Quo(new(big.Rat).SetInt(rounded), factor)
+}
+
+// AmountFormat controls how FormatAmount renders amounts, e.g. "1,234.5 BTC"
+// with ThousandsSeparator ",", TrimZeros and Ticker set.
+type AmountFormat struct {
+	// ThousandsSeparator groups the integer digits, empty for none.
+	ThousandsSeparator string
+	// DecimalSeparator defaults to ".".
+	DecimalSeparator string
+	// TrimZeros drops trailing zeros of the fraction.
+	TrimZeros bool
+	// Ticker appends the currency ticker.
+	Ticker bool
+	// Rounding is applied to the currency's decimals, RoundDown by default.
+	Rounding RoundingMode
+}
+
+// FormatAmount renders amount with the currency's decimals, e.g.
+// "0.00010000" for BTC. Unknown currencies use 8 decimals.
+func FormatAmount(amount *big.Rat, c CryptoCurrency) string {
+	return AmountFormat{}.Format(amount, c)
+}
+
+// Format renders amount with the currency's decimals per the format.
+func (f AmountFormat) Format(amount *big.Rat, c CryptoCurrency) string {
+	decimals := 8
+	if info, ok := c.Info(); ok {
+		decimals = info.Decimals
+	}
+	if amount == nil {
+		amount = new(big.Rat)
+	}
+
+	str := round(amount, decimals, f.Rounding).FloatString(decimals)
+	sign := ""
+	if strings.HasPrefix(str, "-") {
+		sign, str = "-", str[1:]
+	}
+
+	intPart, frac, _ := strings.Cut(str, ".")
+	if f.TrimZeros {
+		frac = strings.TrimRight(frac, "0")
+	}
+
+	if f.ThousandsSeparator != "" {
+		var grouped strings.Builder
+		for i, digit := range intPart {
+			if i > 0 && (len(intPart)-i)%3 == 0 {
+				grouped.WriteString(f.ThousandsSeparator)
+			}
+			grouped.WriteRune(digit)
+		}
+		intPart = grouped.String()
+	}
+
+	out := sign + intPart
+	if frac != "" {
+		sep := f.DecimalSeparator
+		if sep == "" {
+			sep = "."
+		}
+		out += sep + frac
+	}
+	if f.Ticker {
+		out += " " + string(c)
+	}
+
+	return out
+}
@@ -0,0 +1,73 @@
+package goexch
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open, upstream is failing")
+
+// circuitBreaker short-circuits requests after repeated upstream failures.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int           // Consecutive failures before opening
+	cooldown  time.Duration // Time to stay open before a trial request
+	failures  int           // Current consecutive failures
+	openedAt  time.Time     // When the breaker last opened
+	trial     bool          // Whether a trial request is in flight
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow reports whether a request may be sent.
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.failures < cb.threshold {
+		return true
+	}
+
+	// Open: let a single trial request through once the cooldown elapsed
+	if cb.trial || time.Since(cb.openedAt) < cb.cooldown {
+		return false
+	}
+	cb.trial = true
+	return true
+}
+
+// abort releases a trial request that was allowed but never sent, e.g.
+// because the request could not be built, without counting a failure.
+func (cb *circuitBreaker) abort() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.trial = false
+}
+
+// success resets the breaker.
+func (cb *circuitBreaker) success() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures = 0
+	cb.trial = false
+}
+
+// failure records a failed request and opens the breaker at the threshold.
+func (cb *circuitBreaker) failure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.trial = false
+	if cb.failures >= cb.threshold {
+		cb.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,112 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTrialRejectedByRateLimiter(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	const cooldown = 20 * time.Millisecond
+	c := New("")
+	c.Endpoints([]string{srv.URL})
+	c.NoRateLimit()
+	c.CircuitBreaker(1, cooldown)
+
+	if _, err := c.Status(); err == nil {
+		t.Fatal("expected the first request to fail")
+	}
+	if _, err := c.Status(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	time.Sleep(cooldown)
+
+	// The trial after the cooldown is rejected by an empty limiter
+	c.RateLimiter(1, time.Hour)
+	c.rateLimiter.Allow()
+	if _, err := c.Status(); !errors.Is(err, RateLimitExceeded) {
+		t.Fatalf("expected RateLimitExceeded, got %v", err)
+	}
+
+	c.NoRateLimit()
+	if _, err := c.Status(); err != nil {
+		t.Fatalf("expected the trial to get through, got %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestCircuitBreakerTrialWithBadRequest(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	const cooldown = 20 * time.Millisecond
+	c := New("")
+	c.Endpoints([]string{srv.URL})
+	c.NoRateLimit()
+	c.CircuitBreaker(1, cooldown)
+
+	if _, err := c.Status(); err == nil {
+		t.Fatal("expected the first request to fail")
+	}
+	time.Sleep(cooldown)
+
+	// The trial fails before it is sent
+	c.Endpoints([]string{"%zz"})
+	var reqErr *RequestError
+	if _, err := c.Status(); !errors.As(err, &reqErr) {
+		t.Fatalf("expected a RequestError, got %v", err)
+	}
+
+	c.Endpoints([]string{srv.URL})
+	if _, err := c.Status(); err != nil {
+		t.Fatalf("expected the trial to get through, got %v", err)
+	}
+}
+
+func TestCircuitBreakerIgnoresCancelledRequests(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+		w.Write([]byte(`{}`))
+	})
+	c.CircuitBreaker(2, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err := c.StatusContext(ctx)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+		}
+	}
+
+	if _, err := c.Status(); err != nil {
+		t.Fatalf("expected cancelled requests not to open the breaker, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package goexch
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// cacheablePaths lists the slow-changing endpoints whose responses may be
+// cached. Order lookups are never cached.
+var cacheablePaths = map[string]bool{
+	"volume": true,
+	"status": true,
+	"rates":  true,
+}
+
+// Cache stores response bodies of cacheable endpoints. Implementations must
+// be safe for concurrent use.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, body []byte, ttl time.Duration)
+}
+
+// MemoryCache is an in-memory Cache.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryEntry)}
+}
+
+// Get returns the body stored under key, if it has not expired.
+func (mc *MemoryCache) Get(key string) ([]byte, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	entry, ok := mc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(mc.entries, key)
+		return nil, false
+	}
+
+	return entry.body, true
+}
+
+// Set stores body under key for ttl.
+func (mc *MemoryCache) Set(key string, body []byte, ttl time.Duration) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.entries[key] = memoryEntry{body: body, expires: time.Now().Add(ttl)}
+}
+
+// Cache serves Volume, Status, Networks and Rates from cache for ttl. A nil
+// cache disables caching. Once an entry expired, it is revalidated with
+// If-None-Match or If-Modified-Since if the server sent an ETag or
+// Last-Modified header, and reused if the server answers 304 Not Modified.
+// Validators are kept for the most recent maxValidators responses.
+func (c *Client) Cache(cache Cache, ttl time.Duration) {
+	c.cache = cache
+	c.cacheTTL = ttl
+	c.validators = &validatorStore{entries: make(map[string]*conditional)}
+}
+
+type conditionalKey struct{}
+
+// conditional holds the validators of a cached response. For a request, body
+// is the response to reuse when the server answers 304; etag and
+// lastModified are updated from the new response.
+type conditional struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// maxValidators bounds the number of responses kept for revalidation.
+const maxValidators = 64
+
+// validatorStore remembers the validators of cached responses by cache key,
+// evicting the oldest entries beyond maxValidators. The bodies are the slices
+// handed to the Cache, so they are not copied.
+type validatorStore struct {
+	mu      sync.Mutex
+	entries map[string]*conditional
+	order   []string // Keys from oldest to newest
+}
+
+// get returns a copy of the validators stored for key, empty if none are.
+func (vs *validatorStore) get(key string) *conditional {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if stored, ok := vs.entries[key]; ok {
+		cond := *stored
+		return &cond
+	}
+	return &conditional{}
+}
+
+// put stores the validators of a response, or forgets key if it has none.
+func (vs *validatorStore) put(key string, cond *conditional, body []byte) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	vs.forget(key)
+	if cond.etag == "" && cond.lastModified == "" {
+		return
+	}
+
+	vs.entries[key] = &conditional{etag: cond.etag, lastModified: cond.lastModified, body: body}
+	vs.order = append(vs.order, key)
+	for len(vs.order) > maxValidators {
+		delete(vs.entries, vs.order[0])
+		vs.order = vs.order[1:]
+	}
+}
+
+// forget removes key. vs.mu must be held.
+func (vs *validatorStore) forget(key string) {
+	if _, ok := vs.entries[key]; !ok {
+		return
+	}
+	delete(vs.entries, key)
+	for i, k := range vs.order {
+		if k == key {
+			vs.order = append(vs.order[:i], vs.order[i+1:]...)
+			break
+		}
+	}
+}
+
+type bypassCacheKey struct{}
+
+// BypassCache returns a context that makes requests skip the cache.
+func BypassCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+// cacheKey returns the cache key of a request, or false if it must not be
+// cached.
+func (c *Client) cacheKey(ctx context.Context, path, method string, params map[string]string) (string, bool) {
+	if c.cache == nil || method != http.MethodGet || !cacheablePaths[path] {
+		return "", false
+	}
+	if bypass, _ := ctx.Value(bypassCacheKey{}).(bool); bypass {
+		return "", false
+	}
+
+	q := url.Values{}
+	for key, value := range params {
+		q.Set(key, value)
+	}
+
+	return path + "?" + q.Encode(), true
+}
@@ -0,0 +1,61 @@
+package goexch
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCacheRevalidation(t *testing.T) {
+	const body = `{"status":"ok"}`
+	var conditions []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		conditions = append(conditions, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			// Some CDNs label empty 304s as compressed
+			w.Header().Set("Content-Encoding", "gzip")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(body))
+	})
+	c.Cache(NewMemoryCache(), time.Nanosecond)
+
+	for i := 0; i < 2; i++ {
+		status, err := c.Status()
+		if err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+		if status["status"] != "ok" {
+			t.Fatalf("request %d: expected the cached body, got %v", i+1, status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if len(conditions) != 2 || conditions[0] != "" || conditions[1] != `"v1"` {
+		t.Fatalf("expected a plain request and one with If-None-Match, got %q", conditions)
+	}
+	if raw := string(c.LastRawResponse()); raw != body {
+		t.Fatalf("expected the last raw response to be kept on 304, got %q", raw)
+	}
+}
+
+func TestValidatorStoreEviction(t *testing.T) {
+	vs := &validatorStore{entries: make(map[string]*conditional)}
+	for i := 0; i < maxValidators+10; i++ {
+		vs.put(fmt.Sprint(i), &conditional{etag: "x"}, []byte("body"))
+	}
+	vs.put(fmt.Sprint(maxValidators), &conditional{}, nil)
+
+	if len(vs.entries) != maxValidators-1 || len(vs.order) != len(vs.entries) {
+		t.Fatalf("expected %d entries, got %d (%d ordered)", maxValidators-1, len(vs.entries), len(vs.order))
+	}
+	if cond := vs.get("0"); cond.etag != "" {
+		t.Fatal("expected the oldest entry to be evicted")
+	}
+	if cond := vs.get(fmt.Sprint(maxValidators + 9)); cond.etag != "x" {
+		t.Fatal("expected the newest entry to be kept")
+	}
+}
@@ -1,89 +1,752 @@
 package goexch
 
 import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/goccy/go-json"
+	"github.com/Hyrting/goexch/internal/json"
 )
 
 var RateLimitExceeded = errors.New("rate limit exceeded, please wait")
 
+// DefaultTimeout is the overall request timeout of the default HTTP client.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultMaxResponseSize is the default limit of a response body in bytes.
+const DefaultMaxResponseSize = 4 << 20
+
+// batchConcurrency bounds the number of parallel requests made by batch calls.
+const batchConcurrency = 4
+
 // Client represents the API client with rate limiting.
 type Client struct {
 	baseURL     string
-	apiKey      string
+	apiKey      string // Not sent, as exch.cx currently requires no key
 	client      *http.Client
 	rateLimiter *RateLimiter // Added rate limiter
+	headers     map[string]string
+	breaker     *circuitBreaker
+	dryRun      bool
+	idempotency *idempotencyCache
+	baseCtx     context.Context
+	observer    Observer
+	prices      PriceSource
+	maxBody     int64
+	retry       *retryPolicy
+	ownLimiter  bool // Whether Close should close rateLimiter
+	requestID   func() string
+	cache       Cache
+	cacheTTL    time.Duration
+	validators  *validatorStore
+	skipStates  bool
+	labels      map[CryptoCurrency]string
+	explorers   map[CryptoCurrency]string
+	unsupported map[CryptoCurrency][]CryptoCurrency
+	coalescer   *coalescer
+	pathPrefix  string
+	weights     map[string]int
+	refunds     RefundAddressProvider
+	slots       chan struct{} // Bounds concurrent requests
+	inFlight    atomic.Int64
+	slippage    *big.Rat // Percent
+	unsafeLogs  bool
+	params      map[string]string // Sent with every request
+	onState     func(before, after OrderState, order *OrderResponse)
+
+	haltMu sync.Mutex
+	halt   context.Context // Cancelled by CancelAll
+	stop   context.CancelFunc
+
+	rawMu   sync.Mutex
+	lastRaw []byte
+
+	baseMu    sync.Mutex
+	endpoints []string // Failover base URLs
 }
 
-// New initializes and returns a new Client with rate limiting.
+// New initializes and returns a new Client. Requests are rate limited to
+// DefaultRateLimitRequests per DefaultRateLimitPeriod; use RateLimiter to
+// change the budget or NoRateLimit to disable limiting. Surrounding
+// whitespace, such as a trailing newline from an env file, is trimmed from
+// key; a blank key leaves the client unauthenticated, which is sufficient
+// for the public exch.cx API. exch.cx does not issue API keys or per-key
+// quotas, so the key is not sent and there is nothing to rotate between.
 func New(key string) *Client {
-	return &Client{
+	halt, stop := context.WithCancel(context.Background())
+
+	c := &Client{
+		halt:        halt,
+		stop:        stop,
 		baseURL:     "https://exch.cx/api",
-		apiKey:      key,
-		client:      &http.Client{},
-		rateLimiter: nil,
+		apiKey:      strings.TrimSpace(key),
+		client:      newHTTPClient(),
+		idempotency: newIdempotencyCache(DefaultIdempotencyTTL),
+		observer:    NopObserver{},
+		maxBody:     DefaultMaxResponseSize,
+		headers: map[string]string{
+			"Content-Type":     "application/json",
+			"User-Agent":       DefaultUserAgent,
+			"X-Requested-With": "XMLHttpRequest",
+		},
+	}
+	c.DefaultRateLimit()
+
+	return c
+}
+
+// NewPublic returns a Client without an API key. Every exch.cx endpoint is
+// public, so it supports all methods, including order creation.
+func NewPublic() *Client {
+	return New("")
+}
+
+// newHTTPClient returns an HTTP client tuned for talking to a single host.
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = 10
+	transport.IdleConnTimeout = 90 * time.Second
+
+	return &http.Client{
+		Timeout:       DefaultTimeout,
+		Transport:     transport,
+		CheckRedirect: RedirectSameHost.check,
 	}
 }
 
+// Client sets the HTTP client used for requests. The client's own redirect
+// policy is kept, so call RedirectPolicy afterwards to restore the
+// RedirectSameHost default.
 func (c *Client) Client(client *http.Client) {
 	c.client = client
 }
 
+// Timeout sets the overall timeout of the HTTP client, zero meaning none. It
+// applies to a copy of the current HTTP client, leaving a client passed to
+// Client unchanged, so call it after Client.
+func (c *Client) Timeout(timeout time.Duration) {
+	client := *c.client
+	client.Timeout = timeout
+	c.client = &client
+}
+
 func (c *Client) RateLimiter(max int, interval time.Duration) {
 	c.rateLimiter = NewRateLimiter(max, interval)
+	c.ownLimiter = true
 }
 
-func (c *Client) request(path, method string, params map[string]string) (int, []byte, error) {
-	// Enforce rate limiting
-	if c.rateLimiter != nil {
-		if !c.rateLimiter.Allow() {
-			return 0, nil, RateLimitExceeded
+// TickingRateLimiter is like RateLimiter but uses NewTickingRateLimiter. Its
+// goroutine is stopped by Close.
+func (c *Client) TickingRateLimiter(max int, interval time.Duration) {
+	c.rateLimiter = NewTickingRateLimiter(max, interval)
+	c.ownLimiter = true
+}
+
+// SharedRateLimiter makes the client draw tokens from rl. Passing the same
+// limiter to several clients makes them share one budget, matching the single
+// server-side quota of the host; RateLimiter is safe for concurrent use.
+// Close does not close a shared limiter.
+func (c *Client) SharedRateLimiter(rl *RateLimiter) {
+	c.rateLimiter = rl
+	c.ownLimiter = false
+}
+
+// RateLimited reports whether the next request costing one token would be
+// rejected by the rate limiter. It is always false when no rate limiter is
+// configured. Use RateLimitedFor for endpoints with a higher weight.
+func (c *Client) RateLimited() bool {
+	return c.RateLimitedFor("")
+}
+
+// RateLimitedFor is like RateLimited but for a request to endpoint, e.g.
+// "create", taking its weight from EndpointWeights into account.
+func (c *Client) RateLimitedFor(endpoint string) bool {
+	if c.rateLimiter == nil {
+		return false
+	}
+	return c.rateLimiter.Tokens() < c.weight(strings.Trim(endpoint, "/"))
+}
+
+// NoRateLimit disables client-side rate limiting, leaving the request rate
+// entirely to the caller.
+func (c *Client) NoRateLimit() {
+	if c.rateLimiter != nil && c.ownLimiter {
+		c.rateLimiter.Close()
+	}
+	c.rateLimiter = nil
+	c.ownLimiter = false
+}
+
+// EndpointWeights overrides the number of rate limiter tokens requests to the
+// given endpoints cost, e.g. {"create": 3}, on top of DefaultEndpointWeights.
+// A weight above the burst of the rate limiter is capped at the burst, so the
+// endpoint still gets through once the bucket is full.
+func (c *Client) EndpointWeights(weights map[string]int) {
+	if c.weights == nil {
+		c.weights = make(map[string]int, len(weights))
+	}
+	for path, weight := range weights {
+		c.weights[strings.Trim(path, "/")] = weight
+	}
+}
+
+// weight returns the number of tokens a request to path costs. A weight above
+// the burst could never be allowed, so it is capped at the burst.
+// c.rateLimiter must not be nil.
+func (c *Client) weight(path string) int {
+	weight, ok := c.weights[path]
+	if !ok {
+		weight, ok = DefaultEndpointWeights[path]
+	}
+	if !ok {
+		weight = 1
+	}
+	return min(weight, c.rateLimiter.max)
+}
+
+// DefaultRateLimit applies the DefaultRateLimitRequests per
+// DefaultRateLimitPeriod preset.
+func (c *Client) DefaultRateLimit() {
+	c.RateLimiter(DefaultRateLimitRequests, DefaultRateLimitPeriod/DefaultRateLimitRequests)
+}
+
+// Headers merges the given headers into the ones sent with every request.
+// An empty value removes the header, e.g. {"X-Requested-With": ""}.
+func (c *Client) Headers(headers map[string]string) {
+	for key, value := range headers {
+		key = http.CanonicalHeaderKey(key)
+		if value == "" {
+			delete(c.headers, key)
+			continue
+		}
+		c.headers[key] = value
+	}
+}
+
+// DefaultParams merges the given parameters into the ones sent with every
+// request, e.g. a tracking parameter. Parameters passed by a method take
+// precedence over them. An empty value removes the parameter.
+func (c *Client) DefaultParams(params map[string]string) {
+	if c.params == nil {
+		c.params = make(map[string]string, len(params))
+	}
+	for key, value := range params {
+		if value == "" {
+			delete(c.params, key)
+			continue
+		}
+		c.params[key] = value
+	}
+}
+
+// CircuitBreaker makes the client fail fast with ErrCircuitOpen after
+// threshold consecutive failures, until cooldown has elapsed. A threshold
+// below 1 disables the breaker.
+func (c *Client) CircuitBreaker(threshold int, cooldown time.Duration) {
+	if threshold < 1 {
+		c.breaker = nil
+		return
+	}
+	c.breaker = newCircuitBreaker(threshold, cooldown)
+}
+
+// DryRun toggles dry-run mode, in which Order, Refund, ConfirmRefund,
+// SetRefundAddress, RevalidateAddress and Remove validate their input but return a synthesized
+// response instead of calling the API.
+func (c *Client) DryRun(enabled bool) {
+	c.dryRun = enabled
+}
+
+// PathPrefix sets a prefix inserted between the base URL and the endpoint
+// paths, e.g. "v2" to send requests to https://exch.cx/api/v2/status.
+func (c *Client) PathPrefix(prefix string) {
+	c.pathPrefix = strings.Trim(prefix, "/")
+}
+
+// SkipStateChecks disables fetching the order before Refund, ConfirmRefund,
+// SetRefundAddress, RevalidateAddress and Remove to check that its state
+// allows the action. This saves a request per call, leaving invalid actions
+// to be rejected by the server.
+func (c *Client) SkipStateChecks(skip bool) {
+	c.skipStates = skip
+}
+
+// IdempotencyTTL sets how long orders are remembered by their
+// OrderOptions.IdempotencyKey.
+func (c *Client) IdempotencyTTL(ttl time.Duration) {
+	c.idempotency = newIdempotencyCache(ttl)
+}
+
+// BaseContext sets a parent context for all requests. Once it is cancelled,
+// in-flight requests are aborted and new ones fail immediately.
+func (c *Client) BaseContext(ctx context.Context) {
+	c.baseCtx = ctx
+}
+
+// MaxResponseSize limits the size of (decompressed) response bodies. Larger
+// responses fail with ErrResponseTooLarge. Bodies are read whole before
+// decoding; exch.cx has no order history or other list endpoints, and its
+// largest response, Rates, stays far below DefaultMaxResponseSize.
+func (c *Client) MaxResponseSize(bytes int64) {
+	c.maxBody = bytes
+}
+
+// RequestIDFunc sets a generator for an X-Request-ID header sent with every
+// request. The ID is also reported to InfoObserver and in APIError, to
+// correlate client and upstream logs. A nil fn sends no header.
+func (c *Client) RequestIDFunc(fn func() string) {
+	c.requestID = fn
+}
+
+// Observer sets the Observer notified about every request. A nil observer
+// disables observation.
+func (c *Client) Observer(observer Observer) {
+	if observer == nil {
+		observer = NopObserver{}
+	}
+	c.observer = observer
+}
+
+// LastRawResponse returns the body of the most recent response, giving access
+// to fields the typed responses do not cover yet. With concurrent calls it is
+// the body of whichever request finished last.
+func (c *Client) LastRawResponse() []byte {
+	c.rawMu.Lock()
+	defer c.rawMu.Unlock()
+
+	return append([]byte(nil), c.lastRaw...)
+}
+
+// Close releases the idle connections held by the HTTP client and stops the
+// goroutine of a TickingRateLimiter. The client should not be used after
+// Close.
+func (c *Client) Close() error {
+	c.client.CloseIdleConnections()
+	if c.rateLimiter != nil && c.ownLimiter {
+		c.rateLimiter.Close()
+	}
+	return nil
+}
+
+// CancelAll aborts all in-flight requests. Until Reset is called, new
+// requests fail with ErrClientClosed.
+func (c *Client) CancelAll() {
+	c.haltMu.Lock()
+	defer c.haltMu.Unlock()
+
+	c.stop()
+}
+
+// Reset allows requests again after CancelAll.
+func (c *Client) Reset() {
+	c.haltMu.Lock()
+	defer c.haltMu.Unlock()
+
+	c.halt, c.stop = context.WithCancel(context.Background())
+}
+
+// withBase returns ctx, additionally cancelled when any of parents is done.
+// Nil parents are ignored.
+func withBase(ctx context.Context, parents ...context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	stops := make([]func() bool, 0, len(parents))
+	for _, parent := range parents {
+		if parent == nil {
+			continue
 		}
+		stops = append(stops, context.AfterFunc(parent, func() {
+			cancel(context.Cause(parent))
+		}))
 	}
 
-	fullURL := fmt.Sprintf("%s/%s", c.baseURL, path)
+	return ctx, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancel(nil)
+	}
+}
+
+func (c *Client) request(ctx context.Context, path, method string, params map[string]string) (int, []byte, error) {
+	c.haltMu.Lock()
+	halt := c.halt
+	c.haltMu.Unlock()
+
+	if halt.Err() != nil {
+		return 0, nil, ErrClientClosed
+	}
+
+	ctx, cancel := withBase(ctx, c.baseCtx, halt)
+	defer cancel()
+
+	if meta := metaFrom(ctx); meta != nil {
+		start := time.Now()
+		*meta = Meta{TokensRemaining: -1}
+		defer func() {
+			meta.Latency = time.Since(start)
+			if c.rateLimiter != nil {
+				meta.TokensRemaining = c.rateLimiter.Tokens()
+			}
+		}()
+	}
+
+	key, cacheable := c.cacheKey(ctx, path, method, params)
+	if cacheable {
+		if body, ok := c.cache.Get(key); ok {
+			if meta := metaFrom(ctx); meta != nil {
+				meta.StatusCode, meta.Cached = http.StatusOK, true
+			}
+			return http.StatusOK, body, nil
+		}
+	}
+
+	// Revalidate an expired cache entry instead of fetching it again
+	var cond *conditional
+	if cacheable {
+		cond = c.validators.get(key)
+		ctx = context.WithValue(ctx, conditionalKey{}, cond)
+	}
 
-	req, err := http.NewRequest(method, fullURL, nil)
+	var (
+		statusCode int
+		body       []byte
+		err        error
+	)
+	if key, ok := c.coalesceKey(path, method, params); ok {
+		statusCode, body, err = c.coalescer.do(ctx, key, func(shared context.Context) (int, []byte, error) {
+			// Still stopped by CancelAll and the base context
+			shared, cancel := withBase(shared, c.baseCtx, halt)
+			defer cancel()
+			return c.requestUncached(shared, path, method, params)
+		})
+	} else {
+		statusCode, body, err = c.requestUncached(ctx, path, method, params)
+	}
+	if cacheable && err == nil {
+		if statusCode == http.StatusNotModified {
+			statusCode, body = http.StatusOK, cond.body
+		}
+		c.cache.Set(key, body, c.cacheTTL)
+		c.validators.put(key, cond, body)
+	}
+
+	return statusCode, body, err
+}
+
+// requestUncached sends the request, retrying it per the retry policy.
+func (c *Client) requestUncached(ctx context.Context, path, method string, params map[string]string) (int, []byte, error) {
+	var requestID string
+	if c.requestID != nil {
+		requestID = c.requestID()
+	}
+
+	if !c.retry.enabled() || !retryablePaths[path] {
+		return c.send(ctx, path, method, params, requestID)
+	}
+
+	return c.retry.do(ctx, func() (int, []byte, error) {
+		return c.send(ctx, path, method, params, requestID)
+	})
+}
+
+// send makes a single request attempt. Responses with a non-200 status are
+// returned along with an APIError.
+func (c *Client) send(ctx context.Context, path, method string, params map[string]string, requestID string) (int, []byte, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	release, err := c.acquire(ctx)
 	if err != nil {
-		return 0, []byte{}, err
+		return 0, nil, err
+	}
+	defer release()
+
+	// Enforce rate limiting before the breaker, so a rejected request does
+	// not take the breaker's trial
+	if c.rateLimiter != nil {
+		if !c.rateLimiter.AllowNContext(ctx, c.weight(path)) {
+			if err := ctx.Err(); err != nil {
+				return 0, nil, err
+			}
+			return 0, nil, RateLimitExceeded
+		}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	// Every return below must record a result or abort the trial
+	if c.breaker != nil {
+		if !c.breaker.allow() {
+			return 0, nil, ErrCircuitOpen
+		}
+	}
 
-	q := req.URL.Query()
+	// url.Values escapes reserved characters such as '+', '&' and '%', so
+	// IDs and addresses reach the server verbatim
+	q := url.Values{}
+	for key, value := range c.params {
+		q.Set(key, value)
+	}
 	for key, value := range params {
 		q.Set(key, value)
 	}
-	req.URL.RawQuery = q.Encode()
 
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return 0, []byte{}, err
+	if meta := metaFrom(ctx); meta != nil {
+		meta.RequestID = requestID
+	}
+
+	info := RequestInfo{Endpoint: path, RequestID: requestID, Params: c.logParams(params)}
+	start := time.Now()
+
+	// Fail over to the next base URL on transport errors
+	var res *http.Response
+	bases := c.bases()
+	for i, base := range bases {
+		req, err := c.newRequest(ctx, base, path, method, q, requestID)
+		if err != nil {
+			if c.breaker != nil {
+				c.breaker.abort()
+			}
+			return 0, []byte{}, &RequestError{Err: err}
+		}
+
+		info.BytesSent = int64(len(req.URL.RawQuery))
+		if req.ContentLength > 0 {
+			info.BytesSent += req.ContentLength
+		}
+
+		res, err = c.client.Do(req)
+		if err == nil {
+			c.useBase(base)
+			break
+		}
+		if ctx.Err() != nil || i == len(bases)-1 || !failoverSafe(method, err) {
+			c.recordFailure(ctx, info, start)
+			return 0, []byte{}, &TransportError{Err: err}
+		}
+	}
+	defer res.Body.Close()
+	info.Status = res.StatusCode
+	if meta := metaFrom(ctx); meta != nil {
+		meta.StatusCode = res.StatusCode
+		meta.Date, _ = http.ParseTime(res.Header.Get("Date"))
 	}
 
-	body, err := io.ReadAll(res.Body)
+	// Count the bytes received on the wire, before decompression
+	received := &countingReader{ReadCloser: res.Body}
+	res.Body = received
+
+	body, err := c.readBody(res)
+	info.BytesReceived = received.n
+	if meta := metaFrom(ctx); meta != nil {
+		meta.BytesSent, meta.BytesReceived = info.BytesSent, info.BytesReceived
+	}
 	if err != nil {
+		c.recordFailure(ctx, info, start)
 		return 0, []byte{}, err
 	}
 
+	c.recordResult(info, start, res.StatusCode < http.StatusInternalServerError)
+
+	// Keep the body a 304 revalidated
+	if res.StatusCode != http.StatusNotModified {
+		c.rawMu.Lock()
+		c.lastRaw = body
+		c.rawMu.Unlock()
+	}
+
+	if cond, _ := ctx.Value(conditionalKey{}).(*conditional); cond != nil {
+		if res.StatusCode == http.StatusNotModified && cond.body != nil {
+			return res.StatusCode, nil, nil
+		}
+		cond.etag = res.Header.Get("ETag")
+		cond.lastModified = res.Header.Get("Last-Modified")
+	}
+
+	if res.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: res.StatusCode, RequestID: requestID, Body: body}
+		if inMaintenance(res, body) {
+			return res.StatusCode, body, &MaintenanceError{ResumeAt: retryAfter(res.Header.Get("Retry-After"), time.Now()), Err: apiErr}
+		}
+		return res.StatusCode, body, apiErr
+	}
+
 	return res.StatusCode, body, nil
 }
 
-// Volume fetches 24-hour volume data.
-func (c *Client) Volume() (*GetVolumeResponse, error) {
-	statusCode, body, err := c.request("volume", http.MethodGet, nil)
+// newRequest builds a request to path below base.
+func (c *Client) newRequest(ctx context.Context, base, path, method string, q url.Values, requestID string) (*http.Request, error) {
+	fullURL, err := url.JoinPath(base, c.pathPrefix, path)
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf("error: received status code %d", statusCode)
+	// POST requests carry the parameters as a form body, keeping them out
+	// of the URL and server access logs
+	var reqBody io.Reader
+	if method == http.MethodPost {
+		reqBody = strings.NewReader(q.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compression is negotiated and decoded here rather than relying on
+	// the transport, which custom transports may have disabled
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+	if requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req.URL.RawQuery = q.Encode()
+	}
+
+	if cond, _ := ctx.Value(conditionalKey{}).(*conditional); cond != nil && cond.body != nil {
+		if cond.etag != "" {
+			req.Header.Set("If-None-Match", cond.etag)
+		}
+		if cond.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cond.lastModified)
+		}
+	}
+
+	return req, nil
+}
+
+// Call sends a request to path, relative to the API base URL, and decodes the
+// JSON response into out. It goes through the same rate limiting, circuit
+// breaking and status checks as the built-in methods, for endpoints the
+// client does not cover yet. out may be nil to discard the body.
+func (c *Client) Call(ctx context.Context, path, method string, params map[string]string, out interface{}) error {
+	_, body, err := c.request(ctx, strings.TrimPrefix(path, "/"), method, params)
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+// countingReader counts the bytes read from a response body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// readBody reads and decompresses the response body, failing with
+// ErrResponseTooLarge above the maximum size.
+func (c *Client) readBody(res *http.Response) ([]byte, error) {
+	// A 304 has no body to decode, and an empty body may still claim an
+	// encoding, e.g. from CDNs
+	if res.StatusCode == http.StatusNotModified {
+		return []byte{}, nil
+	}
+	buffered := bufio.NewReader(res.Body)
+	if _, err := buffered.Peek(1); err == io.EOF {
+		return []byte{}, nil
+	}
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{buffered, res.Body}
+
+	reader, err := decodeBody(res)
+	if err != nil {
+		return nil, &ReadError{Err: err}
+	}
+	defer reader.Close()
+
+	body, err := io.ReadAll(io.LimitReader(reader, c.maxBody+1))
+	if err != nil {
+		return nil, &ReadError{Err: err}
+	}
+	if int64(len(body)) > c.maxBody {
+		return nil, ErrResponseTooLarge
+	}
+	return body, nil
+}
+
+// decodeBody returns a reader decompressing the body per its Content-Encoding.
+func decodeBody(res *http.Response) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		return gzip.NewReader(res.Body)
+	case "deflate":
+		return zlib.NewReader(res.Body)
+	}
+	return io.NopCloser(res.Body), nil
+}
+
+// recordResult feeds the outcome of a request to the observer and the
+// circuit breaker.
+func (c *Client) recordResult(info RequestInfo, start time.Time, ok bool) {
+	info.Duration = time.Since(start)
+	observe(c.observer, info)
+
+	if c.breaker == nil {
+		return
+	}
+	if ok {
+		c.breaker.success()
+	} else {
+		c.breaker.failure()
+	}
+}
+
+// recordFailure records a request that got no usable response. A request
+// cancelled by its caller says nothing about the upstream, so it only
+// releases the breaker's trial instead of counting as a failure.
+func (c *Client) recordFailure(ctx context.Context, info RequestInfo, start time.Time) {
+	if ctx.Err() == nil {
+		c.recordResult(info, start, false)
+		return
+	}
+
+	info.Duration = time.Since(start)
+	observe(c.observer, info)
+	if c.breaker != nil {
+		c.breaker.abort()
+	}
+}
+
+// Volume fetches 24-hour volume data. The API only exposes this rolling
+// snapshot; there is no endpoint for historical volume.
+func (c *Client) Volume() (*GetVolumeResponse, error) {
+	return c.VolumeContext(context.Background())
+}
+
+// VolumeContext is like Volume but uses ctx for the request.
+func (c *Client) VolumeContext(ctx context.Context) (*GetVolumeResponse, error) {
+	_, body, err := c.request(ctx, "volume", http.MethodGet, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	var result *GetVolumeResponse
@@ -94,15 +757,36 @@ func (c *Client) Volume() (*GetVolumeResponse, error) {
 	return result, nil
 }
 
-// Status retrieves network statuses.
-func (c *Client) Status() (map[string]interface{}, error) {
-	statusCode, body, err := c.request("status", http.MethodGet, nil)
+// VolumeFor fetches the 24-hour volume of a single currency.
+func (c *Client) VolumeFor(currency CryptoCurrency) (*Volume, error) {
+	return c.VolumeForContext(context.Background(), currency)
+}
+
+// VolumeForContext is like VolumeFor but uses ctx for the request.
+func (c *Client) VolumeForContext(ctx context.Context, currency CryptoCurrency) (*Volume, error) {
+	volumes, err := c.VolumeContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf("error: received status code %d", statusCode)
+	volume := volumes.volume(currency)
+	if volume == nil {
+		return nil, fmt.Errorf("%w: no volume for %s", ErrUnsupportedCurrency, currency)
+	}
+
+	return volume, nil
+}
+
+// Status retrieves network statuses.
+func (c *Client) Status() (map[string]interface{}, error) {
+	return c.StatusContext(context.Background())
+}
+
+// StatusContext is like Status but uses ctx for the request.
+func (c *Client) StatusContext(ctx context.Context) (map[string]interface{}, error) {
+	_, body, err := c.request(ctx, "status", http.MethodGet, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	var result map[string]interface{}
@@ -113,10 +797,189 @@ func (c *Client) Status() (map[string]interface{}, error) {
 	return result, nil
 }
 
-// Order creates a new exchange order.
+// Networks retrieves network statuses as typed values. Entries that cannot be
+// parsed are reported in the error map instead of failing the whole call.
+func (c *Client) Networks() (map[CryptoCurrency]*NetworkStatus, map[CryptoCurrency]error, error) {
+	return c.NetworksContext(context.Background())
+}
+
+// NetworksContext is like Networks but uses ctx for the request.
+func (c *Client) NetworksContext(ctx context.Context) (map[CryptoCurrency]*NetworkStatus, map[CryptoCurrency]error, error) {
+	_, body, err := c.request(ctx, "status", http.MethodGet, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var raw map[CryptoCurrency]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	statuses := make(map[CryptoCurrency]*NetworkStatus, len(raw))
+	errs := make(map[CryptoCurrency]error)
+	for currency, data := range raw {
+		var status *NetworkStatus
+		if err := json.Unmarshal(data, &status); err != nil {
+			errs[currency] = err
+			continue
+		}
+		if status == nil {
+			errs[currency] = fmt.Errorf("no status for currency %s", currency)
+			continue
+		}
+		statuses[currency] = status
+	}
+
+	return statuses, errs, nil
+}
+
+// Order creates a new exchange order. The parameters are sent as a POST
+// form body, so addresses do not end up in URLs or access logs.
 func (c *Client) Order(from, to CryptoCurrency, address string, opts *OrderOptions) (*CreateOrderResposnse, error) {
-	if from == "" || to == "" || address == "" {
-		return nil, fmt.Errorf("from, to, and address are required")
+	return c.OrderContext(context.Background(), from, to, address, opts)
+}
+
+// OrderContext is like Order but uses ctx for the request.
+func (c *Client) OrderContext(ctx context.Context, from, to CryptoCurrency, address string, opts *OrderOptions) (*CreateOrderResposnse, error) {
+	opts, err := c.withRefundAddress(from, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := buildOrderParams(from, to, address, opts)
+	if err != nil {
+		return nil, err
+	}
+	if pair := (Pair{From: from, To: to}); !c.PairSupported(from, to) {
+		return nil, fmt.Errorf("%w: pair %s", ErrUnsupportedCurrency, pair)
+	}
+
+	if c.dryRun {
+		return &CreateOrderResposnse{Warnings: orderWarnings(opts), DryRun: true}, nil
+	}
+
+	var key string
+	if opts != nil && opts.IdempotencyKey != "" {
+		key = opts.IdempotencyKey
+		result, release, err := c.idempotency.claim(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			return result, nil
+		}
+		defer release()
+	}
+
+	body, err := c.createOrder(ctx, params, key)
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+
+	var result *CreateOrderResposnse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	if result != nil {
+		result.Warnings = append(result.Warnings, orderWarnings(opts)...)
+	}
+
+	if opts != nil && opts.IdempotencyKey != "" && result != nil {
+		c.idempotency.put(opts.IdempotencyKey, result)
+	}
+
+	return result, nil
+}
+
+// createOrder sends the create request. With an idempotency key, failed
+// attempts are retried per the retry policy, except when the failed response
+// still reports the created order.
+func (c *Client) createOrder(ctx context.Context, params map[string]string, key string) ([]byte, error) {
+	if key == "" || !c.retry.enabled() {
+		_, body, err := c.request(ctx, "create", http.MethodPost, params)
+		return body, err
+	}
+
+	var (
+		attempts int
+		firstErr error
+	)
+	_, body, err := c.retry.do(ctx, func() (int, []byte, error) {
+		attempts++
+		statusCode, body, err := c.request(ctx, "create", http.MethodPost, params)
+		if err != nil && createdOrderID(body) != "" {
+			// The order exists despite the error, so do not create another
+			return http.StatusOK, body, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		return statusCode, body, err
+	})
+
+	if err == nil && attempts > 1 {
+		warn(c.observer, "create", fmt.Sprintf(
+			"order %s was created by attempt %d after %v; exch.cx may have created an order for a failed attempt too",
+			c.redact(createdOrderID(body)), attempts, firstErr))
+	}
+	return body, err
+}
+
+// createdOrderID returns the order ID reported in a create response body, or
+// "" if there is none.
+func createdOrderID(body []byte) string {
+	var created struct {
+		OrderID string `json:"orderid"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return ""
+	}
+	return created.OrderID
+}
+
+// orderWarnings derives the warnings of an order created with opts.
+func orderWarnings(opts *OrderOptions) []string {
+	var warnings []string
+	if opts == nil || strings.TrimSpace(opts.RefundAddress) == "" {
+		warnings = append(warnings, "no refund address set, a failed exchange needs SetRefundAddress to be refunded")
+	}
+	if opts != nil && opts.FeeOption == FeeSlow {
+		warnings = append(warnings, "slow fee option chosen, the payout may take hours to confirm")
+	}
+	return warnings
+}
+
+// buildOrderParams validates the order inputs and assembles the create
+// query parameters.
+func buildOrderParams(from, to CryptoCurrency, address string, opts *OrderOptions) (map[string]string, error) {
+	address = strings.TrimSpace(address)
+	var refundAddress string
+	if opts != nil {
+		refundAddress = strings.TrimSpace(opts.RefundAddress)
+	}
+
+	verr := &ValidationError{}
+	if from == "" {
+		verr.add("from", "is required", ErrUnsupportedCurrency)
+	}
+	if to == "" {
+		verr.add("to", "is required", ErrUnsupportedCurrency)
+	}
+	if address == "" {
+		verr.add("address", "is required", ErrMissingAddress)
+	} else if !ValidAddress(to, address) {
+		verr.add("address", fmt.Sprintf("is not a valid %s address", to), ErrInvalidAddress)
+	}
+	// Refunds are sent back on the source chain
+	if refundAddress != "" && !ValidAddress(from, refundAddress) {
+		verr.add("refund_address", fmt.Sprintf("is not a valid %s address", from), ErrInvalidAddress)
+	}
+	var optsErr *ValidationError
+	if errors.As(opts.Validate(), &optsErr) {
+		verr.Fields = append(verr.Fields, optsErr.Fields...)
+	}
+	if err := verr.err(); err != nil {
+		return nil, err
 	}
 
 	params := map[string]string{
@@ -126,8 +989,8 @@ func (c *Client) Order(from, to CryptoCurrency, address string, opts *OrderOptio
 	}
 
 	if opts != nil {
-		if opts.RefundAddress != "" {
-			params["refund_address"] = opts.RefundAddress
+		if refundAddress != "" {
+			params["refund_address"] = refundAddress
 		}
 		if opts.RateMode != "" {
 			params["rate_mode"] = opts.RateMode
@@ -138,73 +1001,221 @@ func (c *Client) Order(from, to CryptoCurrency, address string, opts *OrderOptio
 		if opts.FeeOption != "" {
 			params["fee_option"] = opts.FeeOption
 		}
-		if opts.Aggregation != nil {
+		switch {
+		case opts.AggregationMode == AggregationYes:
+			params["aggregation"] = "yes"
+		case opts.AggregationMode == AggregationNo:
+			params["aggregation"] = "no"
+		case opts.Aggregation != nil:
 			params["aggregation"] = map[bool]string{true: "yes", false: "no"}[*opts.Aggregation]
 		}
 	}
 
-	statusCode, body, err := c.request("create", http.MethodGet, params)
+	return params, nil
+}
+
+// GetOrder fetches order details. Orders can only be looked up by ID; exch.cx
+// offers no lookup by deposit transaction, so the ID has to be kept, e.g.
+// with a Receipt.
+func (c *Client) GetOrder(id string) (*OrderResponse, error) {
+	return c.GetOrderContext(context.Background(), id)
+}
+
+// GetOrderContext is like GetOrder but uses ctx for the request.
+func (c *Client) GetOrderContext(ctx context.Context, id string) (*OrderResponse, error) {
+	id, err := requireID(id)
 	if err != nil {
-		return nil, fmt.Errorf("request error: %v", err)
+		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf("error: status %d", statusCode)
+	params := map[string]string{"orderid": id}
+
+	statusCode, body, err := c.request(ctx, "order", http.MethodGet, params)
+	if statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, id)
 	}
 
-	var result *CreateOrderResposnse
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+
+	var result *OrderResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("unmarshal error: %v", err)
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	// A null body carries no order, so callers never see a nil order
+	if result == nil {
+		return nil, fmt.Errorf("%w: %s: empty response", ErrOrderNotFound, id)
 	}
 
 	return result, nil
 }
 
-// GetOrder fetches order details.
-func (c *Client) GetOrder(id string) (*OrderResponse, error) {
-	if id == "" {
-		return nil, fmt.Errorf("id is required")
+// OrderExists reports whether an order exists, inspecting only the status of
+// the lookup without decoding the order. exch.cx does not answer HEAD
+// requests, so a GET is sent.
+func (c *Client) OrderExists(id string) (bool, error) {
+	return c.OrderExistsContext(context.Background(), id)
+}
+
+// OrderExistsContext is like OrderExists but uses ctx for the request.
+func (c *Client) OrderExistsContext(ctx context.Context, id string) (bool, error) {
+	id, err := requireID(id)
+	if err != nil {
+		return false, err
 	}
 
 	params := map[string]string{"orderid": id}
 
-	statusCode, body, err := c.request("order", http.MethodGet, params)
+	statusCode, _, err := c.request(ctx, "order", http.MethodGet, params)
+	switch {
+	case statusCode == http.StatusNotFound:
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("request error: %w", err)
+	}
+
+	return true, nil
+}
+
+// DepositAddress returns the address to fund the order with, or
+// ErrDepositAddressNotReady if exch.cx has not assigned one yet.
+func (c *Client) DepositAddress(id string) (string, error) {
+	return c.DepositAddressContext(context.Background(), id)
+}
+
+// DepositAddressContext is like DepositAddress but uses ctx for the request.
+func (c *Client) DepositAddressContext(ctx context.Context, id string) (string, error) {
+	order, err := c.GetOrderContext(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("request error: %v", err)
+		return "", err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf("error: status %d", statusCode)
+	if !depositAddressReady(order) {
+		return "", ErrDepositAddressNotReady
 	}
 
-	var result *OrderResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("unmarshal error: %v", err)
+	return order.FromAddr, nil
+}
+
+// depositAddressReady reports whether the order has a deposit address.
+// exch.cx reports "_GENERATING_" while the address is being created.
+func depositAddressReady(order *OrderResponse) bool {
+	return order.FromAddr != "" && order.FromAddr != "_GENERATING_"
+}
+
+// RefreshOrder re-fetches an order to pick up its latest rate. The API has no
+// dedicated refresh endpoint; GetOrder already returns the live rate of
+// RateDynamic orders. The returned delta is the new rate minus the previous
+// one, or nil if either rate could not be parsed.
+func (c *Client) RefreshOrder(previous *OrderResponse) (*OrderResponse, *big.Rat, error) {
+	return c.RefreshOrderContext(context.Background(), previous)
+}
+
+// RefreshOrderContext is like RefreshOrder but uses ctx for the request.
+func (c *Client) RefreshOrderContext(ctx context.Context, previous *OrderResponse) (*OrderResponse, *big.Rat, error) {
+	if previous == nil {
+		return nil, nil, fmt.Errorf("order is required")
 	}
 
-	return result, nil
+	order, err := c.GetOrderContext(ctx, previous.Orderid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oldRate, ok := new(big.Rat).SetString(previous.Rate)
+	if !ok {
+		return order, nil, nil
+	}
+	newRate, ok := new(big.Rat).SetString(order.Rate)
+	if !ok {
+		return order, nil, nil
+	}
+
+	return order, newRate.Sub(newRate, oldRate), nil
+}
+
+// GetOrders fetches the details of several orders in parallel. The API has no
+// multi-order lookup, so each ID costs one request against the rate limiter.
+// Orders that could not be fetched are reported in the error map.
+func (c *Client) GetOrders(ids []string) (map[string]*OrderResponse, map[string]error) {
+	return c.GetOrdersContext(context.Background(), ids)
+}
+
+// GetOrdersContext is like GetOrders but uses ctx for the requests.
+func (c *Client) GetOrdersContext(ctx context.Context, ids []string) (map[string]*OrderResponse, map[string]error) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, batchConcurrency)
+		orders = make(map[string]*OrderResponse)
+		errs   = make(map[string]error)
+		seen   = make(map[string]bool)
+	)
+
+	for _, id := range ids {
+		// Fetch duplicate IDs only once
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			order, err := c.GetOrderContext(ctx, id)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[id] = err
+				return
+			}
+			orders[id] = order
+		}(id)
+	}
+	wg.Wait()
+
+	return orders, errs
 }
 
 // Refund initiates a refund for an order.
 func (c *Client) Refund(id string) (*ResultResponse, error) {
-	if id == "" {
-		return nil, fmt.Errorf("id is required")
+	return c.RefundContext(context.Background(), id)
+}
+
+// RefundContext is like Refund but uses ctx for the requests.
+func (c *Client) RefundContext(ctx context.Context, id string) (*ResultResponse, error) {
+	id, err := requireID(id)
+	if err != nil {
+		return nil, err
 	}
 
 	params := map[string]string{"orderid": id}
 
-	statusCode, body, err := c.request("order/refund", http.MethodGet, params)
-	if err != nil {
-		return nil, fmt.Errorf("request error: %v", err)
+	if c.dryRun {
+		return &ResultResponse{Result: true, DryRun: true}, nil
+	}
+
+	if _, err := c.checkState(ctx, id, ActionRefund); err != nil {
+		return nil, err
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf("error: status %d", statusCode)
+	statusCode, body, err := c.request(ctx, "order/refund", http.MethodGet, params)
+	if statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
 	}
 
 	var result *ResultResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("unmarshal error: %v", err)
+		return nil, fmt.Errorf("unmarshal error: %w", err)
 	}
 
 	return result, nil
@@ -212,33 +1223,119 @@ func (c *Client) Refund(id string) (*ResultResponse, error) {
 
 // ConfirmRefund confirms a refund.
 func (c *Client) ConfirmRefund(id string) (*ResultResponse, error) {
-	if id == "" {
-		return nil, fmt.Errorf("id is required")
+	return c.ConfirmRefundContext(context.Background(), id)
+}
+
+// ConfirmRefundContext is like ConfirmRefund but uses ctx for the requests.
+func (c *Client) ConfirmRefundContext(ctx context.Context, id string) (*ResultResponse, error) {
+	id, err := requireID(id)
+	if err != nil {
+		return nil, err
 	}
 
 	params := map[string]string{"orderid": id}
 
-	statusCode, body, err := c.request("order/refund_confirm", http.MethodGet, params)
+	if c.dryRun {
+		return &ResultResponse{Result: true, DryRun: true}, nil
+	}
+
+	if _, err := c.checkState(ctx, id, ActionConfirmRefund); err != nil {
+		return nil, err
+	}
+
+	statusCode, body, err := c.request(ctx, "order/refund_confirm", http.MethodGet, params)
+	if statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, id)
+	}
+
 	if err != nil {
-		return nil, fmt.Errorf("request error: %v", err)
+		return nil, fmt.Errorf("request error: %w", err)
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf("error: status %d", statusCode)
+	var result *ResultResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+
+	return result, nil
+}
+
+// SetRefundAddress supplies the refund address of an order that entered
+// REFUND_REQUEST without one. exch.cx takes the address along with the refund
+// confirmation, so this confirms the refund to the given address.
+func (c *Client) SetRefundAddress(id, address string) (*ResultResponse, error) {
+	return c.SetRefundAddressContext(context.Background(), id, address)
+}
+
+// SetRefundAddressContext is like SetRefundAddress but uses ctx for the
+// requests.
+func (c *Client) SetRefundAddressContext(ctx context.Context, id, address string) (*ResultResponse, error) {
+	id, address = strings.TrimSpace(id), strings.TrimSpace(address)
+
+	verr := &ValidationError{}
+	if id == "" {
+		verr.add("id", "is required", ErrMissingID)
+	}
+	if address == "" {
+		verr.add("address", "is required", ErrMissingAddress)
+	}
+	if err := verr.err(); err != nil {
+		return nil, err
+	}
+
+	// Required parameters
+	params := map[string]string{
+		"orderid":        id,
+		"refund_address": address,
+	}
+
+	if c.dryRun {
+		return &ResultResponse{Result: true, DryRun: true}, nil
+	}
+
+	if _, err := c.checkState(ctx, id, ActionConfirmRefund); err != nil {
+		return nil, err
+	}
+
+	// Make the request
+	statusCode, body, err := c.request(ctx, "order/refund_confirm", http.MethodGet, params)
+	if statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 
 	var result *ResultResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("unmarshal error: %v", err)
+		return nil, fmt.Errorf("error unmarshaling response: %v", err)
 	}
 
 	return result, nil
 }
 
-// RevalidateAddress revalidates an address.
+// RevalidateAddress revalidates an address. The order is fetched first to
+// check that it is in TO_ADDRESS_INVALID and that address matches the format
+// of its to_currency, unless state checks are skipped.
 func (c *Client) RevalidateAddress(id, address string) (*ResultResponse, error) {
-	if id == "" || address == "" {
-		return nil, fmt.Errorf("id and address are required")
+	return c.RevalidateAddressContext(context.Background(), id, address)
+}
+
+// RevalidateAddressContext is like RevalidateAddress but uses ctx for the
+// requests.
+func (c *Client) RevalidateAddressContext(ctx context.Context, id, address string) (*ResultResponse, error) {
+	id, address = strings.TrimSpace(id), strings.TrimSpace(address)
+
+	verr := &ValidationError{}
+	if id == "" {
+		verr.add("id", "is required", ErrMissingID)
+	}
+	if address == "" {
+		verr.add("address", "is required", ErrMissingAddress)
+	}
+	if err := verr.err(); err != nil {
+		return nil, err
 	}
 
 	// Required parameters
@@ -247,14 +1344,30 @@ func (c *Client) RevalidateAddress(id, address string) (*ResultResponse, error)
 		"to_address": address,
 	}
 
-	// Make the request
-	statusCode, body, err := c.request("order/revalidate_address", http.MethodGet, params)
+	if c.dryRun {
+		return &ResultResponse{Result: true, DryRun: true}, nil
+	}
+
+	order, err := c.checkState(ctx, id, ActionRevalidateAddress)
 	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+		return nil, err
+	}
+	if order != nil && !ValidAddress(order.ToCurrency, address) {
+		return nil, &ValidationError{Fields: []FieldError{{
+			Field:  "address",
+			Reason: fmt.Sprintf("is not a valid %s address", order.ToCurrency),
+			Err:    ErrInvalidAddress,
+		}}}
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf("error: received status code %d", statusCode)
+	// Make the request
+	statusCode, body, err := c.request(ctx, "order/revalidate_address", http.MethodGet, params)
+	if statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, id)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 
 	var result *ResultResponse
@@ -265,10 +1378,36 @@ func (c *Client) RevalidateAddress(id, address string) (*ResultResponse, error)
 	return result, nil
 }
 
+// checkState fetches the order and returns ErrActionNotAllowed if its state
+// does not allow action. It returns a nil order when state checks are
+// skipped.
+func (c *Client) checkState(ctx context.Context, id string, action Action) (*OrderResponse, error) {
+	if c.skipStates {
+		return nil, nil
+	}
+
+	order, err := c.GetOrderContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := order.checkAction(action); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
 // Remove deletes order data.
 func (c *Client) Remove(id string) (*ResultResponse, error) {
-	if id == "" {
-		return nil, fmt.Errorf("order id is empty")
+	return c.RemoveContext(context.Background(), id)
+}
+
+// RemoveContext is like Remove but uses ctx for the requests.
+func (c *Client) RemoveContext(ctx context.Context, id string) (*ResultResponse, error) {
+	id, err := requireID(id)
+	if err != nil {
+		return nil, err
 	}
 
 	// Required parameters
@@ -276,14 +1415,22 @@ func (c *Client) Remove(id string) (*ResultResponse, error) {
 		"orderid": id,
 	}
 
+	if c.dryRun {
+		return &ResultResponse{Result: true, DryRun: true}, nil
+	}
+
+	if _, err := c.checkState(ctx, id, ActionRemove); err != nil {
+		return nil, err
+	}
+
 	// Make the request
-	statusCode, body, err := c.request("order/remove", http.MethodGet, params)
-	if err != nil {
-		return nil, fmt.Errorf("error making request: %v", err)
+	statusCode, body, err := c.request(ctx, "order/remove", http.MethodGet, params)
+	if statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, id)
 	}
 
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf("error: received status code %d", statusCode)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
 	}
 
 	var result *ResultResponse
@@ -293,3 +1440,56 @@ func (c *Client) Remove(id string) (*ResultResponse, error) {
 
 	return result, nil
 }
+
+// RemoveAll removes several orders in parallel, continuing past individual
+// failures. Each removal costs two requests against the rate limiter, one to
+// check the order's state and one to remove it, or only the latter with
+// SkipStateChecks. Orders that could not be
+// removed are reported in the returned map.
+func (c *Client) RemoveAll(ids []string) map[string]error {
+	return c.RemoveAllContext(context.Background(), ids)
+}
+
+// RemoveAllContext is like RemoveAll but uses ctx for the requests.
+func (c *Client) RemoveAllContext(ctx context.Context, ids []string) map[string]error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, batchConcurrency)
+		errs = make(map[string]error)
+		seen = make(map[string]bool)
+	)
+
+	for _, id := range ids {
+		// Remove duplicate IDs only once
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := c.RemoveContext(ctx, id)
+			if err == nil {
+				err = result.Err()
+			}
+			if err == nil && (result == nil || !result.Result) {
+				err = fmt.Errorf("order %s was not removed", id)
+			}
+			if err == nil {
+				return
+			}
+
+			mu.Lock()
+			errs[id] = err
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	return errs
+}
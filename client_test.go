@@ -0,0 +1,631 @@
+package goexch
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client sending its requests to a test server
+// running handler, without rate limiting.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c := New("")
+	c.Endpoints([]string{srv.URL})
+	c.NoRateLimit()
+	return c
+}
+
+// roundTripFunc is an http.RoundTripper calling itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// errReader fails every read.
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestRequestBuildError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	})
+
+	err := c.Call(context.Background(), "status", "BAD METHOD", nil, nil)
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("expected a RequestError, got %T: %v", err, err)
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	c := New("")
+	c.NoRateLimit()
+	c.Client(&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})})
+
+	_, err := c.Status()
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("expected a TransportError, got %T: %v", err, err)
+	}
+}
+
+func TestReadError(t *testing.T) {
+	c := New("")
+	c.NoRateLimit()
+	c.Client(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(errReader{}),
+			Request:    req,
+		}, nil
+	})})
+
+	_, err := c.Status()
+	var readErr *ReadError
+	if !errors.As(err, &readErr) {
+		t.Fatalf("expected a ReadError, got %T: %v", err, err)
+	}
+}
+
+func TestContentEncoding(t *testing.T) {
+	const body = `{"BTC":{"volume":"12.5"}}`
+	tests := []struct {
+		encoding string
+		writer   func(io.Writer) io.WriteCloser
+	}{
+		{encoding: "gzip", writer: func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }},
+		{encoding: "deflate", writer: func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }},
+		{encoding: "identity", writer: func(w io.Writer) io.WriteCloser { return nopWriteCloser{w} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", tt.encoding)
+				zw := tt.writer(w)
+				zw.Write([]byte(body))
+				zw.Close()
+			})
+
+			volume, err := c.Volume()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if volume.Bitcoin == nil || volume.Bitcoin.Volume != "12.5" {
+				t.Fatalf("expected a BTC volume of 12.5, got %+v", volume.Bitcoin)
+			}
+		})
+	}
+}
+
+// nopWriteCloser is an io.WriteCloser whose Close does nothing.
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestDecodeError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write([]byte("not gzip"))
+	})
+
+	_, err := c.Status()
+	var readErr *ReadError
+	if !errors.As(err, &readErr) {
+		t.Fatalf("expected a ReadError, got %T: %v", err, err)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"bad request"}`))
+	})
+
+	_, err := c.Status()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, apiErr.StatusCode)
+	}
+	if !strings.Contains(string(apiErr.Body), "bad request") {
+		t.Errorf("expected the response body, got %q", apiErr.Body)
+	}
+}
+
+func TestCustomHTTPClientIsUsed(t *testing.T) {
+	var calls int
+	c := New("")
+	c.NoRateLimit()
+	c.Client(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+			Request:    req,
+		}, nil
+	})})
+
+	if _, err := c.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the custom client to send 1 request, got %d", calls)
+	}
+}
+
+// trickyValue contains characters with a special meaning in query strings
+// and form bodies.
+const trickyValue = "a+b&c=d%20e f"
+
+// captureParams returns a handler recording the decoded query or form
+// parameters of every request, answering with body.
+func captureParams(got *[]map[string]string, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		params := make(map[string]string)
+		for key := range r.Form {
+			params[key] = r.Form.Get(key)
+		}
+		*got = append(*got, params)
+		w.Write([]byte(body))
+	}
+}
+
+func TestParamsEscaping(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *Client) error
+		want map[string]string
+	}{
+		{
+			name: "GetOrder",
+			call: func(c *Client) error {
+				_, err := c.GetOrder(trickyValue)
+				return err
+			},
+			want: map[string]string{"orderid": trickyValue},
+		},
+		{
+			name: "Order",
+			call: func(c *Client) error {
+				_, err := c.Order(Bitcoin, Ethereum, testETHAddress, &OrderOptions{ReferrerID: trickyValue})
+				return err
+			},
+			want: map[string]string{"ref": trickyValue, "to_address": testETHAddress},
+		},
+		{
+			name: "Refund",
+			call: func(c *Client) error {
+				_, err := c.Refund(trickyValue)
+				return err
+			},
+			want: map[string]string{"orderid": trickyValue},
+		},
+		{
+			name: "SetRefundAddress",
+			call: func(c *Client) error {
+				_, err := c.SetRefundAddress(trickyValue, "addr+with&odd=chars%2F and spaces")
+				return err
+			},
+			want: map[string]string{"orderid": trickyValue, "refund_address": "addr+with&odd=chars%2F and spaces"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []map[string]string
+			c := newTestClient(t, captureParams(&got, `{"orderid":"x","result":true}`))
+			c.SkipStateChecks(true)
+
+			if err := tt.call(c); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("expected 1 request, got %d", len(got))
+			}
+			for key, want := range tt.want {
+				if got[0][key] != want {
+					t.Errorf("%s: expected %q, got %q", key, want, got[0][key])
+				}
+			}
+		})
+	}
+}
+
+func TestWeightAboveBurst(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"orderid":"x"}`))
+	})
+	c.RateLimiter(1, time.Hour)
+
+	// create weighs 2 tokens by default
+	if _, err := c.Order(Bitcoin, Ethereum, testETHAddress, nil); err != nil {
+		t.Fatalf("expected the order to be allowed with a burst of 1, got %v", err)
+	}
+	if _, err := c.Order(Bitcoin, Ethereum, testETHAddress, nil); !errors.Is(err, RateLimitExceeded) {
+		t.Fatalf("expected RateLimitExceeded once the bucket is empty, got %v", err)
+	}
+}
+
+func TestContextCancelsInFlightRequest(t *testing.T) {
+	arrived := make(chan struct{})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		close(arrived)
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-arrived
+		cancel()
+	}()
+
+	_, err := c.StatusContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("expected a TransportError, got %T", err)
+	}
+}
+
+func TestContextDeadlineExceeded(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.GetOrderContext(ctx, "abc"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("request outlived its deadline by %v", elapsed)
+	}
+}
+
+func TestCancelledContextSendsNothing(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	})
+	c.RateLimiter(5, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.StatusContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if got := c.rateLimiter.Tokens(); got != 5 {
+		t.Fatalf("expected no token to be taken, %d left", got)
+	}
+}
+
+func TestCustomHTTPClientGetsContext(t *testing.T) {
+	type key struct{}
+	var got interface{}
+	c := New("")
+	c.NoRateLimit()
+	c.Client(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Context().Value(key{})
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+			Request:    req,
+		}, nil
+	})})
+
+	ctx := context.WithValue(context.Background(), key{}, "marker")
+	if _, err := c.StatusContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got != "marker" {
+		t.Fatalf("expected the custom client to receive the request context, got %v", got)
+	}
+}
+
+func TestNewTrimsKey(t *testing.T) {
+	if got := New("key\n").apiKey; got != "key" {
+		t.Errorf("New(%q): got key %q, want %q", "key\n", got, "key")
+	}
+	if got := New(" \t\n").apiKey; got != "" {
+		t.Errorf("New with a blank key: got key %q, want none", got)
+	}
+}
+
+func TestDefaultParamsPrecedence(t *testing.T) {
+	var got []map[string]string
+	c := newTestClient(t, captureParams(&got, `{"orderid":"abc","state":"CREATED"}`))
+	c.DefaultParams(map[string]string{"orderid": "def", "ref": "x"})
+
+	if _, err := c.GetOrder("abc"); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(got))
+	}
+	want := map[string]string{"orderid": "abc", "ref": "x"}
+	if len(got[0]) != len(want) {
+		t.Errorf("expected params %v, got %v", want, got[0])
+	}
+	for key, value := range want {
+		if got[0][key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, got[0][key])
+		}
+	}
+}
+
+func TestBuildOrderParams(t *testing.T) {
+	const refund = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+	yes, no := true, false
+	base := map[string]string{
+		"from_currency": "BTC",
+		"to_currency":   "ETH",
+		"to_address":    testETHAddress,
+	}
+	with := func(key, value string) map[string]string {
+		params := map[string]string{key: value}
+		for k, v := range base {
+			params[k] = v
+		}
+		return params
+	}
+
+	tests := []struct {
+		name string
+		opts *OrderOptions
+		want map[string]string
+	}{
+		{name: "nil options", opts: nil, want: base},
+		{name: "empty options", opts: &OrderOptions{}, want: base},
+		{name: "refund address", opts: &OrderOptions{RefundAddress: " " + refund + "\n"}, want: with("refund_address", refund)},
+		{name: "rate mode", opts: &OrderOptions{RateMode: RateDynamic}, want: with("rate_mode", "dynamic")},
+		{name: "referrer", opts: &OrderOptions{ReferrerID: "partner"}, want: with("ref", "partner")},
+		{name: "fee option", opts: &OrderOptions{FeeOption: FeeFast}, want: with("fee_option", "f")},
+		{name: "aggregation nil", opts: &OrderOptions{Aggregation: nil}, want: base},
+		{name: "aggregation true", opts: &OrderOptions{Aggregation: &yes}, want: with("aggregation", "yes")},
+		{name: "aggregation false", opts: &OrderOptions{Aggregation: &no}, want: with("aggregation", "no")},
+		{name: "aggregation mode yes", opts: &OrderOptions{AggregationMode: AggregationYes}, want: with("aggregation", "yes")},
+		{name: "aggregation mode no", opts: &OrderOptions{AggregationMode: AggregationNo}, want: with("aggregation", "no")},
+		{name: "aggregation mode wins", opts: &OrderOptions{AggregationMode: AggregationNo, Aggregation: &yes}, want: with("aggregation", "no")},
+		{name: "client-side options", opts: &OrderOptions{IdempotencyKey: "key", MinOutput: big.NewRat(1, 1)}, want: base},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildOrderParams(Bitcoin, Ethereum, " "+testETHAddress+" ", tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildOrderParamsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    CryptoCurrency
+		to      CryptoCurrency
+		address string
+		opts    *OrderOptions
+		want    error
+	}{
+		{name: "missing from", to: Ethereum, address: testETHAddress, want: ErrUnsupportedCurrency},
+		{name: "missing address", from: Bitcoin, to: Ethereum, want: ErrMissingAddress},
+		{name: "invalid address", from: Bitcoin, to: Ethereum, address: "0x123", want: ErrInvalidAddress},
+		{name: "invalid refund address", from: Bitcoin, to: Ethereum, address: testETHAddress, opts: &OrderOptions{RefundAddress: testETHAddress}, want: ErrInvalidAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildOrderParams(tt.from, tt.to, tt.address, tt.opts); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	var verr *ValidationError
+	_, err := buildOrderParams(Bitcoin, Ethereum, testETHAddress, &OrderOptions{RateMode: "fast", FeeOption: "x"})
+	if !errors.As(err, &verr) || len(verr.Fields) != 2 {
+		t.Errorf("expected a ValidationError for rate_mode and fee_option, got %v", err)
+	}
+}
+
+func TestNullOrderResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *Client) error
+	}{
+		{name: "GetOrder", call: func(c *Client) error {
+			_, err := c.GetOrder("abc")
+			return err
+		}},
+		{name: "Refund", call: func(c *Client) error {
+			_, err := c.Refund("abc")
+			return err
+		}},
+		{name: "DepositAddress", call: func(c *Client) error {
+			_, err := c.DepositAddress("abc")
+			return err
+		}},
+		{name: "RefreshOrder", call: func(c *Client) error {
+			_, _, err := c.RefreshOrder(&OrderResponse{Orderid: "abc", Rate: "1"})
+			return err
+		}},
+		{name: "WaitForOrder", call: func(c *Client) error {
+			_, err := c.WaitForOrder(context.Background(), "abc", time.Millisecond)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`null`))
+			})
+			if err := tt.call(c); !errors.Is(err, ErrOrderNotFound) {
+				t.Fatalf("expected ErrOrderNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTimeoutKeepsCallerClient(t *testing.T) {
+	own := &http.Client{}
+	c := New("")
+	c.Client(own)
+	c.Timeout(5 * time.Second)
+
+	if own.Timeout != 0 {
+		t.Fatal("Timeout changed the client passed to Client")
+	}
+	if c.client.Timeout != 5*time.Second {
+		t.Fatalf("expected a timeout of 5s, got %v", c.client.Timeout)
+	}
+}
+
+func TestGetOrderNotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		wantHits int32
+		want     error
+	}{
+		{name: "not found is not retried", status: http.StatusNotFound, wantHits: 1, want: ErrOrderNotFound},
+		{name: "server error is retried", status: http.StatusInternalServerError, wantHits: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+				w.WriteHeader(tt.status)
+			})
+			c.Retry(2, time.Millisecond)
+
+			_, err := c.GetOrder("abc")
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			var apiErr *APIError
+			if tt.want == nil && !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %v", err)
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Fatalf("expected %d requests, got %d", tt.wantHits, got)
+			}
+		})
+	}
+}
+
+func TestBlankInputsRejected(t *testing.T) {
+	const blank = " \t\n"
+	tests := []struct {
+		name string
+		call func(c *Client) error
+		want error
+	}{
+		{name: "GetOrder id", want: ErrMissingID, call: func(c *Client) error {
+			_, err := c.GetOrder(blank)
+			return err
+		}},
+		{name: "Refund id", want: ErrMissingID, call: func(c *Client) error {
+			_, err := c.Refund(blank)
+			return err
+		}},
+		{name: "SetRefundAddress id", want: ErrMissingID, call: func(c *Client) error {
+			_, err := c.SetRefundAddress(blank, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq")
+			return err
+		}},
+		{name: "SetRefundAddress address", want: ErrMissingAddress, call: func(c *Client) error {
+			_, err := c.SetRefundAddress("abc", blank)
+			return err
+		}},
+		{name: "RevalidateAddress address", want: ErrMissingAddress, call: func(c *Client) error {
+			_, err := c.RevalidateAddress("abc", blank)
+			return err
+		}},
+		{name: "Order to address", want: ErrMissingAddress, call: func(c *Client) error {
+			_, err := c.Order(Bitcoin, Ethereum, blank, nil)
+			return err
+		}},
+		{name: "Order refund address", want: ErrMissingAddress, call: func(c *Client) error {
+			_, err := c.Order(Bitcoin, Ethereum, testETHAddress, &OrderOptions{RefundAddress: blank})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+			})
+
+			if err := tt.call(c); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+			if got := hits.Load(); got != 0 {
+				t.Errorf("expected no request, got %d", got)
+			}
+		})
+	}
+}
+
+func TestRateLimitedForWeightedEndpoint(t *testing.T) {
+	c := New("")
+	c.NoRateLimit()
+	if c.RateLimited() || c.RateLimitedFor("create") {
+		t.Fatal("expected no rate limiting without a rate limiter")
+	}
+
+	c.RateLimiter(3, time.Hour)
+	c.rateLimiter.AllowN(2)
+
+	if c.RateLimited() {
+		t.Error("expected a request costing one token to be allowed")
+	}
+	if !c.RateLimitedFor("create") {
+		t.Error("expected create, costing two tokens, to be rate limited")
+	}
+	if c.RateLimitedFor("/status") {
+		t.Error("expected status, costing one token, to be allowed")
+	}
+}
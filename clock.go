@@ -0,0 +1,66 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ServerTime returns the current time of the exch.cx server. The API has no
+// time endpoint, so it is read from the Date header of a status request and
+// has second precision.
+func (c *Client) ServerTime() (time.Time, error) {
+	return c.ServerTimeContext(context.Background())
+}
+
+// ServerTimeContext is like ServerTime but uses ctx for the request.
+func (c *Client) ServerTimeContext(ctx context.Context) (time.Time, error) {
+	serverTime, _, err := c.serverTime(ctx)
+	return serverTime, err
+}
+
+// ClockSkew returns how far the server clock is ahead of the local one,
+// negative if it is behind. Pass it to TimeRemainingSkewed for expiry
+// countdowns that are accurate on clients with a wrong clock.
+func (c *Client) ClockSkew() (time.Duration, error) {
+	return c.ClockSkewContext(context.Background())
+}
+
+// ClockSkewContext is like ClockSkew but uses ctx for the request.
+func (c *Client) ClockSkewContext(ctx context.Context) (time.Duration, error) {
+	serverTime, local, err := c.serverTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return serverTime.Sub(local), nil
+}
+
+// serverTime returns the server time along with the local time halfway
+// through the request.
+func (c *Client) serverTime(ctx context.Context) (time.Time, time.Time, error) {
+	ctx, meta := CaptureMeta(BypassCache(ctx))
+
+	start := time.Now()
+	_, _, err := c.request(ctx, "status", http.MethodGet, nil)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if meta.Date.IsZero() {
+		return time.Time{}, time.Time{}, errors.New("response has no Date header")
+	}
+
+	return meta.Date, start.Add(meta.Latency / 2), nil
+}
+
+// TimeRemainingSkewed is like TimeRemaining but corrects the local clock by
+// skew, as returned by Client.ClockSkew.
+func (od *OrderResponse) TimeRemainingSkewed(skew time.Duration) time.Duration {
+	if od.Expires == 0 {
+		return 0
+	}
+	if remaining := od.ExpiresAt().Sub(time.Now().Add(skew)); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package goexch
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// coalescer shares the result of identical concurrent requests.
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+type coalescedCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int // Callers still waiting, guarded by coalescer.mu
+	meta    *Meta
+
+	statusCode int
+	body       []byte
+	err        error
+}
+
+// RequestCoalescing makes concurrent identical GET requests, e.g. several
+// goroutines polling the same order, share a single HTTP call and its
+// result. The shared call is detached from the context of any single caller:
+// each caller stops waiting when its own context is done, and the call is
+// only cancelled once every caller gave up.
+func (c *Client) RequestCoalescing(enabled bool) {
+	if enabled {
+		c.coalescer = &coalescer{calls: make(map[string]*coalescedCall)}
+	} else {
+		c.coalescer = nil
+	}
+}
+
+// do calls fn once per key at a time, handing its result to every caller
+// arriving while it runs. fn gets a context carrying the values of the first
+// caller's ctx but none of its cancellation.
+func (co *coalescer) do(ctx context.Context, key string, fn func(context.Context) (int, []byte, error)) (int, []byte, error) {
+	co.mu.Lock()
+	call, ok := co.calls[key]
+	if !ok {
+		shared, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		// The first caller may leave early, so the call records its own Meta
+		shared, meta := CaptureMeta(shared)
+		call = &coalescedCall{done: make(chan struct{}), cancel: cancel, meta: meta}
+		co.calls[key] = call
+
+		go func() {
+			call.statusCode, call.body, call.err = fn(shared)
+
+			co.mu.Lock()
+			co.forget(key, call)
+			co.mu.Unlock()
+			cancel()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	co.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if meta := metaFrom(ctx); meta != nil {
+			meta.StatusCode = call.meta.StatusCode
+			meta.RequestID = call.meta.RequestID
+			meta.BytesSent, meta.BytesReceived = call.meta.BytesSent, call.meta.BytesReceived
+			meta.Date = call.meta.Date
+		}
+		return call.statusCode, call.body, call.err
+	case <-ctx.Done():
+		co.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody is interested in the result anymore
+			co.forget(key, call)
+			call.cancel()
+		}
+		co.mu.Unlock()
+		return 0, nil, ctx.Err()
+	}
+}
+
+// forget removes call from the running calls if it is still registered for
+// key. co.mu must be held.
+func (co *coalescer) forget(key string, call *coalescedCall) {
+	if co.calls[key] == call {
+		delete(co.calls, key)
+	}
+}
+
+// coalesceKey returns the coalescing key of a request, or false if it must
+// not be coalesced.
+func (c *Client) coalesceKey(path, method string, params map[string]string) (string, bool) {
+	if c.coalescer == nil || method != http.MethodGet {
+		return "", false
+	}
+
+	q := url.Values{}
+	for key, value := range params {
+		q.Set(key, value)
+	}
+
+	return path + "?" + q.Encode(), true
+}
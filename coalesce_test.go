@@ -0,0 +1,117 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingOrderServer returns a client whose order lookups block until
+// release is closed, counting the upstream hits.
+func blockingOrderServer(t *testing.T) (c *Client, hits *atomic.Int32, release chan struct{}) {
+	hits = new(atomic.Int32)
+	release = make(chan struct{})
+	c = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		w.Write([]byte(`{"orderid":"abc","state":"CREATED"}`))
+	})
+	c.RequestCoalescing(true)
+	return c, hits, release
+}
+
+// waitForWaiters blocks until n callers wait for the coalesced call of key.
+func waitForWaiters(t *testing.T, c *Client, key string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		c.coalescer.mu.Lock()
+		call, ok := c.coalescer.calls[key]
+		waiting := ok && call.waiters == n
+		c.coalescer.mu.Unlock()
+		if waiting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d callers", n)
+}
+
+func TestCoalescingSharesOneRequest(t *testing.T) {
+	const n = 10
+	c, hits, release := blockingOrderServer(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			order, err := c.GetOrder("abc")
+			if err == nil && order.Orderid != "abc" {
+				err = errors.New("unexpected order " + order.Orderid)
+			}
+			errs <- err
+		}()
+	}
+
+	waitForWaiters(t, c, "order?orderid=abc", n)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", got)
+	}
+}
+
+func TestCoalescingFollowerDeadline(t *testing.T) {
+	c, _, release := blockingOrderServer(t)
+	defer close(release)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrderContext(firstCtx, "abc")
+		firstErr <- err
+	}()
+	waitForWaiters(t, c, "order?orderid=abc", 1)
+
+	// A follower with a short deadline does not wait for the shared call
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.GetOrderContext(ctx, "abc"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("follower waited %v for the shared call", elapsed)
+	}
+
+	// Cancelling the first caller does not fail the others
+	followerErr := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrder("abc")
+		followerErr <- err
+	}()
+	waitForWaiters(t, c, "order?orderid=abc", 2)
+	cancelFirst()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled for the first caller, got %v", err)
+	}
+
+	release <- struct{}{}
+	if err := <-followerErr; err != nil {
+		t.Fatalf("expected the follower to get the shared result, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package goexch
+
+import "context"
+
+// MaxConcurrency bounds the number of requests sent at the same time to n.
+// Requests beyond the limit wait, respecting their context, until a slot
+// frees. A non-positive n removes the bound. It should be called before the
+// client is used.
+func (c *Client) MaxConcurrency(n int) {
+	if n <= 0 {
+		c.slots = nil
+		return
+	}
+	c.slots = make(chan struct{}, n)
+}
+
+// InFlight returns the number of requests currently being sent.
+func (c *Client) InFlight() int {
+	return int(c.inFlight.Load())
+}
+
+// acquire waits for a request slot. The returned func releases it.
+func (c *Client) acquire(ctx context.Context) (func(), error) {
+	slots := c.slots
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	c.inFlight.Add(1)
+	return func() {
+		c.inFlight.Add(-1)
+		if slots != nil {
+			<-slots
+		}
+	}, nil
+}
@@ -0,0 +1,179 @@
+package goexch
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Hyrting/goexch/internal/json"
+)
+
+// CurrencyInfo describes a supported currency.
+type CurrencyInfo struct {
+	// Decimals is the number of decimal places the currency supports.
+	Decimals int
+	// Name is the human readable name, e.g. "Bitcoin".
+	Name string
+	// Network is the chain the currency is transferred on, e.g. "ERC-20" for USDC.
+	Network string
+	// IsToken reports whether the currency is a token on another chain.
+	IsToken bool
+}
+
+var currencies = map[CryptoCurrency]CurrencyInfo{
+	Monero:           {Decimals: 12, Name: "Monero", Network: "Monero"},
+	Litecoin:         {Decimals: 8, Name: "Litecoin", Network: "Litecoin"},
+	Ethereum:         {Decimals: 18, Name: "Ethereum", Network: "Ethereum"},
+	Dash:             {Decimals: 8, Name: "Dash", Network: "Dash"},
+	BitcoinLightning: {Decimals: 8, Name: "Bitcoin Lightning", Network: "Lightning"},
+	Bitcoin:          {Decimals: 8, Name: "Bitcoin", Network: "Bitcoin"},
+	USDCoinErc20:     {Decimals: 6, Name: "USD Coin", Network: "ERC-20", IsToken: true},
+	TetherErc20:      {Decimals: 6, Name: "Tether", Network: "ERC-20", IsToken: true},
+	Dai:              {Decimals: 18, Name: "Dai", Network: "ERC-20", IsToken: true},
+}
+
+var lenientCurrencies atomic.Bool
+
+// SetLenientCurrencies makes decoding accept currencies missing from the
+// known set instead of failing with ErrUnsupportedCurrency, e.g. to keep
+// working when exch.cx adds a currency before this package does. It applies
+// to all clients and is safe to call while requests are in flight.
+func SetLenientCurrencies(enabled bool) {
+	lenientCurrencies.Store(enabled)
+}
+
+func (c CryptoCurrency) String() string {
+	return string(c)
+}
+
+// MarshalJSON encodes the currency as its ticker.
+func (c CryptoCurrency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(c))
+}
+
+// UnmarshalJSON decodes a ticker, failing with ErrUnsupportedCurrency for
+// unknown currencies unless SetLenientCurrencies enabled them. An empty ticker is
+// accepted as unset.
+func (c *CryptoCurrency) UnmarshalJSON(data []byte) error {
+	var ticker string
+	if err := json.Unmarshal(data, &ticker); err != nil {
+		return err
+	}
+
+	if _, ok := currencies[CryptoCurrency(ticker)]; !ok && ticker != "" && !lenientCurrencies.Load() {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCurrency, ticker)
+	}
+
+	*c = CryptoCurrency(ticker)
+	return nil
+}
+
+// Info returns the metadata for the currency, or false if it is unknown.
+func (c CryptoCurrency) Info() (CurrencyInfo, bool) {
+	info, ok := currencies[c]
+	return info, ok
+}
+
+// CurrencyLabels overrides the display names returned by Label, e.g.
+// {BitcoinLightning: "Lightning"}. Currencies not in labels keep their
+// CurrencyInfo.Name.
+func (c *Client) CurrencyLabels(labels map[CryptoCurrency]string) {
+	c.labels = make(map[CryptoCurrency]string, len(labels))
+	for currency, label := range labels {
+		c.labels[currency] = label
+	}
+}
+
+// Label returns the display name of currency: its label set with
+// CurrencyLabels, its CurrencyInfo.Name, or the ticker for unknown currencies.
+func (c *Client) Label(currency CryptoCurrency) string {
+	if label, ok := c.labels[currency]; ok {
+		return label
+	}
+	if info, ok := currency.Info(); ok {
+		return info.Name
+	}
+	return string(currency)
+}
+
+// Summary is like OrderResponse.Summary but names the currencies by Label,
+// e.g. "order abc123: 0.01 Lightning→Ethereum state=EXCHANGING rate=15.2".
+func (c *Client) Summary(order *OrderResponse) string {
+	return order.summary(c.Label(order.FromCurrency) + "→" + c.Label(order.ToCurrency))
+}
+
+// defaultUnsupportedPairs lists exchanges that are known to be impossible,
+// keyed by the source currency. Clients override it with UnsupportedPairs.
+var defaultUnsupportedPairs = map[CryptoCurrency][]CryptoCurrency{
+	Monero:           {Monero},
+	Litecoin:         {Litecoin},
+	Ethereum:         {Ethereum},
+	Dash:             {Dash},
+	BitcoinLightning: {BitcoinLightning},
+	Bitcoin:          {Bitcoin},
+	USDCoinErc20:     {USDCoinErc20},
+	TetherErc20:      {TetherErc20},
+	Dai:              {Dai},
+}
+
+// PairSupported reports whether exchanging from into to is not known to be
+// impossible by default; see Client.PairSupported for overridden pairs.
+func PairSupported(from, to CryptoCurrency) bool {
+	return pairSupported(defaultUnsupportedPairs, from, to)
+}
+
+// UnsupportedPairs replaces the exchanges known to be impossible, keyed by the
+// source currency, e.g. to follow upstream changes. Order and Estimate reject
+// them before sending a request; pairs not listed are assumed to be
+// supported. A nil map restores the defaults.
+func (c *Client) UnsupportedPairs(pairs map[CryptoCurrency][]CryptoCurrency) {
+	if pairs == nil {
+		c.unsupported = nil
+		return
+	}
+	c.unsupported = make(map[CryptoCurrency][]CryptoCurrency, len(pairs))
+	for from, tos := range pairs {
+		c.unsupported[from] = append([]CryptoCurrency(nil), tos...)
+	}
+}
+
+// PairSupported is like the package-level PairSupported but uses the pairs
+// set with UnsupportedPairs.
+func (c *Client) PairSupported(from, to CryptoCurrency) bool {
+	if c.unsupported == nil {
+		return PairSupported(from, to)
+	}
+	return pairSupported(c.unsupported, from, to)
+}
+
+// pairSupported reports whether to is missing from the unsupported targets
+// of from.
+func pairSupported(unsupported map[CryptoCurrency][]CryptoCurrency, from, to CryptoCurrency) bool {
+	for _, target := range unsupported[from] {
+		if target == to {
+			return false
+		}
+	}
+	return true
+}
+
+// Pair is an exchange direction from one currency into another.
+type Pair struct {
+	From CryptoCurrency
+	To   CryptoCurrency
+}
+
+// String returns the pair like "BTC→ETH".
+func (p Pair) String() string {
+	return string(p.From) + "→" + string(p.To)
+}
+
+// Supported reports whether the pair is not known to be impossible by
+// default, like PairSupported.
+func (p Pair) Supported() bool {
+	return PairSupported(p.From, p.To)
+}
+
+// rateKey returns the key of the pair in the rates response, e.g. "BTC_XMR".
+func (p Pair) rateKey() string {
+	return string(p.From) + "_" + string(p.To)
+}
@@ -0,0 +1,64 @@
+package goexch
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLenientCurrencies(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"orderid":"abc","from_currency":"NEWCOIN","to_currency":"BTC","state":"CREATED"}`))
+	})
+
+	if _, err := c.GetOrder("abc"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Fatalf("strict: expected ErrUnsupportedCurrency, got %v", err)
+	}
+
+	SetLenientCurrencies(true)
+	defer SetLenientCurrencies(false)
+
+	order, err := c.GetOrder("abc")
+	if err != nil {
+		t.Fatalf("lenient: unexpected error: %v", err)
+	}
+	if order.FromCurrency != "NEWCOIN" {
+		t.Errorf("lenient: got from currency %q, want NEWCOIN", order.FromCurrency)
+	}
+}
+
+func TestUnsupportedPairs(t *testing.T) {
+	var hits atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte(`{"BTC_ETH":{"rate":"15","rate_mode":"flat","reserve":"100","svc_fee":"0.5"}}`))
+	})
+
+	if _, err := c.Order(Ethereum, Ethereum, testETHAddress, nil); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("default pairs: expected ErrUnsupportedCurrency, got %v", err)
+	}
+
+	pairs := map[CryptoCurrency][]CryptoCurrency{Bitcoin: {Ethereum}}
+	c.UnsupportedPairs(pairs)
+	pairs[Bitcoin] = nil
+
+	if _, err := c.Estimate(Bitcoin, Ethereum, big.NewRat(1, 1)); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("Estimate: expected ErrUnsupportedCurrency, got %v", err)
+	}
+	if _, err := c.Order(Bitcoin, Ethereum, testETHAddress, nil); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("Order: expected ErrUnsupportedCurrency, got %v", err)
+	}
+	if got := hits.Load(); got != 0 {
+		t.Fatalf("expected no request, got %d", got)
+	}
+	if !c.PairSupported(Ethereum, Ethereum) || !PairSupported(Bitcoin, Ethereum) {
+		t.Error("expected the override to replace the client's pairs only")
+	}
+
+	c.UnsupportedPairs(nil)
+	if c.PairSupported(Ethereum, Ethereum) {
+		t.Error("expected nil to restore the default pairs")
+	}
+}
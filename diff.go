@@ -0,0 +1,53 @@
+package goexch
+
+// OrderChanges lists what changed between two snapshots of an order.
+type OrderChanges struct {
+	// StateChanged is set when the state differs.
+	StateChanged bool
+	// RateChanged is set when the rate differs, e.g. for RateDynamic orders.
+	RateChanged bool
+	// DepositAddressAssigned is set when the deposit address became known.
+	DepositAddressAssigned bool
+	// AmountReceivedChanged is set when the deposited amount differs.
+	AmountReceivedChanged bool
+	// ReceivedIDAppeared is set when the deposit transaction became known.
+	ReceivedIDAppeared bool
+	// AmountSentChanged is set when the paid out amount differs.
+	AmountSentChanged bool
+	// SentIDAppeared is set when the payout transaction became known.
+	SentIDAppeared bool
+}
+
+// Changed reports whether anything changed.
+func (ch OrderChanges) Changed() bool {
+	return ch != OrderChanges{}
+}
+
+// OrderDiff compares two snapshots of the same order, e.g. from consecutive
+// polls. A nil order counts as empty.
+func OrderDiff(before, after *OrderResponse) OrderChanges {
+	if before == nil {
+		before = &OrderResponse{}
+	}
+	if after == nil {
+		after = &OrderResponse{}
+	}
+
+	return OrderChanges{
+		StateChanged:           before.State != after.State,
+		RateChanged:            before.Rate != after.Rate,
+		DepositAddressAssigned: !depositAddressReady(before) && depositAddressReady(after),
+		AmountReceivedChanged:  optional(before.AmountReceived) != optional(after.AmountReceived),
+		ReceivedIDAppeared:     optional(before.ReceivedID) == "" && optional(after.ReceivedID) != "",
+		AmountSentChanged:      optional(before.AmountSent) != optional(after.AmountSent),
+		SentIDAppeared:         optional(before.SentID) == "" && optional(after.SentID) != "",
+	}
+}
+
+// optional returns the value of an optional field, or "" if it is unset.
+func optional(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,222 @@
+package goexch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissingID              = errors.New("order id is required")
+	ErrMissingAddress         = errors.New("address is required")
+	ErrMissingAmount          = errors.New("amount is required")
+	ErrInvalidAddress         = errors.New("invalid address")
+	ErrUnsupportedCurrency    = errors.New("unsupported currency")
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrActionNotAllowed       = errors.New("action not allowed in the order's state")
+	ErrResponseTooLarge       = errors.New("response body exceeds the maximum size")
+	ErrClientClosed           = errors.New("client requests were cancelled")
+	ErrDepositAddressNotReady = errors.New("deposit address not assigned yet")
+	ErrMaintenance            = errors.New("exch.cx is in maintenance")
+)
+
+// RequestError is returned when the HTTP request could not be built.
+type RequestError struct {
+	Err error
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("request error: %v", e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// TransportError is returned when the HTTP request could not be sent.
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("transport error: %v", e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// ReadError is returned when the response body could not be read.
+type ReadError struct {
+	Err error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("read error: %v", e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError describes why a single input field was rejected.
+type FieldError struct {
+	Field  string
+	Reason string
+	// Err is the sentinel error matching the failure, if any.
+	Err error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Reason)
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned when one or more inputs fail local validation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msg := "validation failed: "
+	for i, field := range e.Fields {
+		if i > 0 {
+			msg += ", "
+		}
+		msg += field.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel errors of the failed fields, so that
+// errors.Is(err, ErrMissingID) works on a ValidationError.
+func (e *ValidationError) Unwrap() []error {
+	var errs []error
+	for _, field := range e.Fields {
+		if field.Err != nil {
+			errs = append(errs, field.Err)
+		}
+	}
+	return errs
+}
+
+// add records a failed field. err may be nil.
+func (e *ValidationError) add(field, reason string, err error) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Reason: reason, Err: err})
+}
+
+// err returns the ValidationError if any field failed, or nil.
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// requireID trims the order id and returns a ValidationError if it is blank.
+func requireID(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", &ValidationError{Fields: []FieldError{{Field: "id", Reason: "is required", Err: ErrMissingID}}}
+	}
+	return id, nil
+}
+
+// requireAmount returns a ValidationError for field if amount is nil.
+func requireAmount(field string, amount *big.Rat) error {
+	if amount == nil {
+		return &ValidationError{Fields: []FieldError{{Field: field, Reason: "is required", Err: ErrMissingAmount}}}
+	}
+	return nil
+}
+
+// APIError is returned when the API responds with a non-200 status.
+type APIError struct {
+	StatusCode int
+	// RequestID is the X-Request-ID of the failed request, if one was sent.
+	RequestID string
+	Body      []byte
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("received status code %d (request id %s)", e.StatusCode, e.RequestID)
+	}
+	return fmt.Sprintf("received status code %d", e.StatusCode)
+}
+
+// MaintenanceError is returned when exch.cx answers 503 Service Unavailable
+// with a Retry-After header or a body mentioning maintenance. Other 503s,
+// e.g. from an overloaded proxy, stay plain *APIErrors and are retried. It
+// matches ErrMaintenance and the underlying *APIError.
+type MaintenanceError struct {
+	// ResumeAt is when the server expects to be back per its Retry-After
+	// header, or the zero time if it sent none.
+	ResumeAt time.Time
+	Err      *APIError
+}
+
+func (e *MaintenanceError) Error() string {
+	if !e.ResumeAt.IsZero() {
+		return fmt.Sprintf("%v until %s", ErrMaintenance, e.ResumeAt.Format(time.RFC3339))
+	}
+	return ErrMaintenance.Error()
+}
+
+func (e *MaintenanceError) Unwrap() []error {
+	return []error{ErrMaintenance, e.Err}
+}
+
+// inMaintenance reports whether a response signals maintenance rather than a
+// transient outage.
+func inMaintenance(res *http.Response, body []byte) bool {
+	if res.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+	return res.Header.Get("Retry-After") != "" || bytes.Contains(bytes.ToLower(body), []byte("maintenance"))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date.
+func retryAfter(header string, now time.Time) time.Time {
+	if header == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return date
+	}
+	return time.Time{}
+}
+
+// resultErrors maps the error codes of a ResultResponse to sentinel errors.
+// exch.cx does not document its codes, so unknown ones are expected.
+var resultErrors = map[string]error{
+	"ORDER_NOT_FOUND": ErrOrderNotFound,
+	"INVALID_ADDRESS": ErrInvalidAddress,
+}
+
+// ResultError is the error reported in a ResultResponse. errors.Is matches
+// it against the sentinel error of a known code, e.g. ErrOrderNotFound for
+// "ORDER_NOT_FOUND".
+type ResultError struct {
+	// Code is the raw error string sent by the API.
+	Code string
+}
+
+func (e *ResultError) Error() string {
+	return fmt.Sprintf("api error: %s", e.Code)
+}
+
+func (e *ResultError) Unwrap() error {
+	return resultErrors[strings.ToUpper(strings.TrimSpace(e.Code))]
+}
@@ -0,0 +1,54 @@
+package goexch
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServiceUnavailable(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		body        string
+		maintenance bool
+	}{
+		{name: "overloaded", body: "upstream connect error"},
+		{name: "retry after", header: "120", maintenance: true},
+		{name: "maintenance page", body: "<h1>Scheduled Maintenance</h1>", maintenance: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+				if tt.header != "" {
+					w.Header().Set("Retry-After", tt.header)
+				}
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(tt.body))
+			})
+			c.Retry(2, time.Millisecond)
+
+			_, err := c.Status()
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+				t.Fatalf("expected an APIError with status 503, got %v", err)
+			}
+			if got := errors.Is(err, ErrMaintenance); got != tt.maintenance {
+				t.Fatalf("expected errors.Is(err, ErrMaintenance) to be %t, got %t", tt.maintenance, got)
+			}
+
+			want := int32(3)
+			if tt.maintenance {
+				want = 1
+			}
+			if got := hits.Load(); got != want {
+				t.Fatalf("expected %d requests, got %d", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package goexch
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+
+	"github.com/Hyrting/goexch/internal/json"
+)
+
+// Rate is the current exchange rate of a currency pair.
+type Rate struct {
+	Rate     string `json:"rate"`
+	RateMode string `json:"rate_mode"`
+	Reserve  string `json:"reserve"` // Maximum output currently available
+	SvcFee   string `json:"svc_fee"` // Service fee in percent
+}
+
+// Estimate is the expected result of exchanging an amount.
+type Estimate struct {
+	// Input is the amount sent, in the source currency.
+	Input *big.Rat
+	// Output is the expected amount received after the service fee, in the
+	// target currency. The network fee is not included.
+	Output *big.Rat
+	// Rate is the rate the estimate is based on.
+	Rate *big.Rat
+	// Min and Max bound the output for RateDynamic rates, which may move
+	// before the deposit is exchanged. They widen Output by the client's
+	// slippage buffer and equal Output for RateFlat rates.
+	Min *big.Rat
+	Max *big.Rat
+}
+
+// SlippageBuffer sets by how many percent the output of a dynamic rate may
+// move, widening Estimate.Min and Estimate.Max. exch.cx reports no rate
+// variance, so the buffer is up to the caller; the default is zero. A
+// negative, NaN or infinite percent fails with a ValidationError and leaves
+// the buffer unchanged.
+func (c *Client) SlippageBuffer(percent float64) error {
+	if percent < 0 || math.IsNaN(percent) || math.IsInf(percent, 0) {
+		verr := &ValidationError{}
+		verr.add("percent", fmt.Sprintf("%v is not a non-negative finite percent", percent), nil)
+		return verr
+	}
+
+	c.slippage = new(big.Rat).SetFloat64(percent)
+	return nil
+}
+
+// Rates fetches the current rates of all pairs, keyed like "BTC_XMR".
+func (c *Client) Rates() (map[string]*Rate, error) {
+	return c.RatesContext(context.Background())
+}
+
+// RatesContext is like Rates but uses ctx for the request.
+func (c *Client) RatesContext(ctx context.Context) (map[string]*Rate, error) {
+	_, body, err := c.request(ctx, "rates", http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]*Rate
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Estimate computes the expected output of exchanging amount of from into to
+// at the current rate.
+func (c *Client) Estimate(from, to CryptoCurrency, amount *big.Rat) (*Estimate, error) {
+	return c.EstimateContext(context.Background(), from, to, amount)
+}
+
+// EstimateContext is like Estimate but uses ctx for the request.
+func (c *Client) EstimateContext(ctx context.Context, from, to CryptoCurrency, amount *big.Rat) (*Estimate, error) {
+	if err := requireAmount("amount", amount); err != nil {
+		return nil, err
+	}
+
+	pair := Pair{From: from, To: to}
+	if !c.PairSupported(from, to) {
+		return nil, fmt.Errorf("%w: pair %s", ErrUnsupportedCurrency, pair)
+	}
+
+	rates, err := c.RatesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, ok := rates[pair.rateKey()]
+	if !ok || rate == nil {
+		return nil, fmt.Errorf("%w: no rate for %s", ErrUnsupportedCurrency, pair)
+	}
+
+	estimate, err := rate.estimate(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.withRange(estimate, rate.RateMode), nil
+}
+
+// EstimateMulti is like Estimate for several target currencies. All rates
+// come from a single Rates request, so it costs one request however many
+// targets are given. Targets that cannot be estimated, e.g. unsupported
+// pairs, are reported in the error map instead of failing the whole call.
+func (c *Client) EstimateMulti(from CryptoCurrency, tos []CryptoCurrency, amount *big.Rat) (map[CryptoCurrency]*Estimate, map[CryptoCurrency]error, error) {
+	return c.EstimateMultiContext(context.Background(), from, tos, amount)
+}
+
+// EstimateMultiContext is like EstimateMulti but uses ctx for the request.
+func (c *Client) EstimateMultiContext(ctx context.Context, from CryptoCurrency, tos []CryptoCurrency, amount *big.Rat) (map[CryptoCurrency]*Estimate, map[CryptoCurrency]error, error) {
+	if err := requireAmount("amount", amount); err != nil {
+		return nil, nil, err
+	}
+
+	rates, err := c.RatesContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	estimates := make(map[CryptoCurrency]*Estimate, len(tos))
+	errs := make(map[CryptoCurrency]error)
+	for _, to := range tos {
+		pair := Pair{From: from, To: to}
+		if !c.PairSupported(from, to) {
+			errs[to] = fmt.Errorf("%w: pair %s", ErrUnsupportedCurrency, pair)
+			continue
+		}
+
+		rate, ok := rates[pair.rateKey()]
+		if !ok || rate == nil {
+			errs[to] = fmt.Errorf("%w: no rate for %s", ErrUnsupportedCurrency, pair)
+			continue
+		}
+
+		estimate, err := rate.estimate(amount)
+		if err != nil {
+			errs[to] = err
+			continue
+		}
+		estimates[to] = c.withRange(estimate, rate.RateMode)
+	}
+
+	return estimates, errs, nil
+}
+
+// withRange sets the Min and Max of estimate per the slippage buffer.
+func (c *Client) withRange(estimate *Estimate, mode string) *Estimate {
+	estimate.Min = new(big.Rat).Set(estimate.Output)
+	estimate.Max = new(big.Rat).Set(estimate.Output)
+	if mode == RateFlat || c.slippage == nil {
+		return estimate
+	}
+
+	// output * (100 ± buffer) / 100
+	hundred := big.NewRat(100, 1)
+	estimate.Min.Mul(estimate.Min, new(big.Rat).Sub(hundred, c.slippage))
+	estimate.Min.Quo(estimate.Min, hundred)
+	estimate.Max.Mul(estimate.Max, new(big.Rat).Add(hundred, c.slippage))
+	estimate.Max.Quo(estimate.Max, hundred)
+	if estimate.Min.Sign() < 0 {
+		estimate.Min.SetInt64(0)
+	}
+
+	return estimate
+}
+
+// estimate applies the rate and service fee to amount.
+func (r *Rate) estimate(amount *big.Rat) (*Estimate, error) {
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if !ok {
+		return nil, fmt.Errorf("invalid rate %q", r.Rate)
+	}
+
+	output := new(big.Rat).Mul(amount, rate)
+	if fee, ok := new(big.Rat).SetString(r.SvcFee); ok {
+		// output * (100 - fee) / 100
+		keep := new(big.Rat).Sub(big.NewRat(100, 1), fee)
+		output.Mul(output, keep)
+		output.Quo(output, big.NewRat(100, 1))
+	}
+
+	if reserve, ok := new(big.Rat).SetString(r.Reserve); ok && output.Cmp(reserve) > 0 {
+		return nil, fmt.Errorf("expected output exceeds the available reserve of %s", r.Reserve)
+	}
+
+	return &Estimate{Input: new(big.Rat).Set(amount), Output: output, Rate: rate}, nil
+}
+
+// EstimateFromFiat converts fiatAmount into the required input of from using
+// the configured PriceSource, then estimates the output in to. The input is
+// not checked against the minimum and maximum input of the pair: exch.cx only
+// reports them for created orders, see OrderResponse.MinInput and MaxInput.
+func (c *Client) EstimateFromFiat(fiat string, fiatAmount *big.Rat, from, to CryptoCurrency) (*Estimate, error) {
+	return c.EstimateFromFiatContext(context.Background(), fiat, fiatAmount, from, to)
+}
+
+// EstimateFromFiatContext is like EstimateFromFiat but uses ctx for the
+// price lookup and the request.
+func (c *Client) EstimateFromFiatContext(ctx context.Context, fiat string, fiatAmount *big.Rat, from, to CryptoCurrency) (*Estimate, error) {
+	if err := requireAmount("fiatAmount", fiatAmount); err != nil {
+		return nil, err
+	}
+	price, err := c.price(ctx, from, fiat)
+	if err != nil {
+		return nil, err
+	}
+	if price.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid %s price of %s", fiat, from)
+	}
+
+	input := new(big.Rat).Quo(fiatAmount, price)
+	return c.EstimateContext(ctx, from, to, RoundToPrecision(input, from))
+}
+
+// AcceptsVariableInput reports whether any deposit between MinInput and
+// MaxInput is accepted, as opposed to a fixed amount that has to be sent
+// exactly. The API has no flag for this; the order is taken to require a
+// fixed amount when MinInput equals MaxInput. Unparsable bounds count as
+// variable input.
+func (od *OrderResponse) AcceptsVariableInput() bool {
+	min, ok := new(big.Rat).SetString(od.MinInput)
+	if !ok {
+		return true
+	}
+	max, ok := new(big.Rat).SetString(od.MaxInput)
+	if !ok {
+		return true
+	}
+	return min.Cmp(max) != 0
+}
+
+// IsUnderpaid reports whether a deposit was received that is below MinInput.
+// exch.cx does not exchange such deposits; the order ends up needing a refund,
+// which is requested with Client.Refund and a refund address once the order
+// allows ActionRefund. Topping up the deposit is not supported by the API.
+// Unparsable amounts are not reported as underpaid.
+func (od *OrderResponse) IsUnderpaid() bool {
+	received, ok := od.ReceivedAmount()
+	if !ok {
+		return false
+	}
+	min, ok := new(big.Rat).SetString(od.MinInput)
+	if !ok {
+		return false
+	}
+	return received.Cmp(min) < 0
+}
@@ -0,0 +1,58 @@
+package goexch
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"net/http"
+	"testing"
+)
+
+// fixedPrice is a PriceSource quoting the same price for everything.
+type fixedPrice int64
+
+func (p fixedPrice) Price(CryptoCurrency, string) (*big.Rat, error) {
+	return big.NewRat(int64(p), 1), nil
+}
+
+func TestEstimateNilAmount(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	})
+	c.PriceSource(fixedPrice(50000))
+
+	if _, err := c.Estimate(Bitcoin, Monero, nil); !errors.Is(err, ErrMissingAmount) {
+		t.Errorf("Estimate: expected ErrMissingAmount, got %v", err)
+	}
+	if _, _, err := c.EstimateMulti(Bitcoin, []CryptoCurrency{Monero}, nil); !errors.Is(err, ErrMissingAmount) {
+		t.Errorf("EstimateMulti: expected ErrMissingAmount, got %v", err)
+	}
+	if _, err := c.EstimateFromFiat("USD", nil, Bitcoin, Monero); !errors.Is(err, ErrMissingAmount) {
+		t.Errorf("EstimateFromFiat: expected ErrMissingAmount, got %v", err)
+	}
+}
+
+func TestSlippageBuffer(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"BTC_ETH":{"rate":"15","rate_mode":"dynamic","reserve":"100","svc_fee":"0"}}`))
+	})
+	if err := c.SlippageBuffer(2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, percent := range []float64{-1, math.NaN(), math.Inf(1)} {
+		var verr *ValidationError
+		if err := c.SlippageBuffer(percent); !errors.As(err, &verr) {
+			t.Errorf("%v: expected a ValidationError, got %v", percent, err)
+		}
+	}
+
+	estimate, err := c.Estimate(Bitcoin, Ethereum, big.NewRat(1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.Min.Cmp(estimate.Output) >= 0 || estimate.Max.Cmp(estimate.Output) <= 0 {
+		t.Errorf("expected the 2%% buffer to be kept, got min %s, output %s, max %s",
+			estimate.Min.FloatString(4), estimate.Output.FloatString(4), estimate.Max.FloatString(4))
+	}
+}
@@ -0,0 +1,92 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNetworkUnavailable = errors.New("network is not processing transfers")
+
+// depositTimes is the typical time until a deposit is confirmed enough to be
+// exchanged. The values are rough figures, not reported by exch.cx.
+var depositTimes = map[CryptoCurrency]time.Duration{
+	Monero:           20 * time.Minute,
+	Litecoin:         10 * time.Minute,
+	Ethereum:         5 * time.Minute,
+	Dash:             5 * time.Minute,
+	BitcoinLightning: time.Minute,
+	Bitcoin:          30 * time.Minute,
+	USDCoinErc20:     5 * time.Minute,
+	TetherErc20:      5 * time.Minute,
+	Dai:              5 * time.Minute,
+}
+
+// payoutTimes is the typical time until a payout sent with FeeFast is
+// confirmed.
+var payoutTimes = map[CryptoCurrency]time.Duration{
+	Monero:           20 * time.Minute,
+	Litecoin:         5 * time.Minute,
+	Ethereum:         2 * time.Minute,
+	Dash:             5 * time.Minute,
+	BitcoinLightning: time.Minute,
+	Bitcoin:          10 * time.Minute,
+	USDCoinErc20:     2 * time.Minute,
+	TetherErc20:      2 * time.Minute,
+	Dai:              2 * time.Minute,
+}
+
+// feeFactors scales payoutTimes for the slower fee options.
+var feeFactors = map[string]time.Duration{
+	FeeSlow:   4,
+	FeeMedium: 2,
+	FeeFast:   1,
+}
+
+// exchangeTime is the typical time exch.cx takes to exchange a confirmed
+// deposit and send the payout.
+const exchangeTime = 5 * time.Minute
+
+// EstimateETA returns a rough estimate of how long an order from from to to
+// takes to complete once the deposit is sent, e.g. to show "~45 min" in a
+// UI. It is based on typical confirmation times, not on data from exch.cx,
+// and fails with ErrNetworkUnavailable when the network status reports that
+// deposits of from or payouts of to are not processed. An empty feeOption is
+// treated as FeeFast, the API default.
+func (c *Client) EstimateETA(from, to CryptoCurrency, feeOption string) (time.Duration, error) {
+	return c.EstimateETAContext(context.Background(), from, to, feeOption)
+}
+
+// EstimateETAContext is like EstimateETA but uses ctx for the request.
+func (c *Client) EstimateETAContext(ctx context.Context, from, to CryptoCurrency, feeOption string) (time.Duration, error) {
+	if feeOption == "" {
+		feeOption = FeeFast
+	}
+	factor, ok := feeFactors[feeOption]
+	if !ok {
+		return 0, fmt.Errorf("invalid fee option %q", feeOption)
+	}
+
+	deposit, ok := depositTimes[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, from)
+	}
+	payout, ok := payoutTimes[to]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, to)
+	}
+
+	statuses, _, err := c.NetworksContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if status, ok := statuses[from]; ok && !status.Deposit {
+		return 0, fmt.Errorf("%w: %s deposits", ErrNetworkUnavailable, from)
+	}
+	if status, ok := statuses[to]; ok && !status.Withdrawal {
+		return 0, fmt.Errorf("%w: %s payouts", ErrNetworkUnavailable, to)
+	}
+
+	return deposit + exchangeTime + payout*factor, nil
+}
@@ -0,0 +1,93 @@
+package goexch
+
+import "net/url"
+
+// defaultExplorers maps currencies to the block explorer URL that transaction
+// IDs are appended to. Clients override it with Explorers.
+var defaultExplorers = map[CryptoCurrency]string{
+	Bitcoin:      "https://mempool.space/tx/",
+	Litecoin:     "https://litecoinspace.org/tx/",
+	Ethereum:     "https://etherscan.io/tx/",
+	USDCoinErc20: "https://etherscan.io/tx/",
+	TetherErc20:  "https://etherscan.io/tx/",
+	Dai:          "https://etherscan.io/tx/",
+	Dash:         "https://blockchair.com/dash/transaction/",
+	Monero:       "https://xmrchain.net/tx/",
+}
+
+// ExplorerURL returns a block explorer link for the transaction, or an empty
+// string if txid is empty or the currency has no explorer (e.g. BTCLN). It
+// uses the default explorers; see Client.ExplorerURL for overridden ones.
+func ExplorerURL(txid string, c CryptoCurrency) string {
+	return explorerURL(defaultExplorers, txid, c)
+}
+
+// Explorers overrides the block explorer URLs that transaction IDs are
+// appended to, e.g. {Bitcoin: "https://blockstream.info/tx/"}. Currencies
+// not in explorers keep their default explorer; an empty URL removes it.
+func (c *Client) Explorers(explorers map[CryptoCurrency]string) {
+	c.explorers = make(map[CryptoCurrency]string, len(defaultExplorers)+len(explorers))
+	for currency, base := range defaultExplorers {
+		c.explorers[currency] = base
+	}
+	for currency, base := range explorers {
+		if base == "" {
+			delete(c.explorers, currency)
+			continue
+		}
+		c.explorers[currency] = base
+	}
+}
+
+// ExplorerURL is like the package-level ExplorerURL but uses the explorers
+// set with Explorers.
+func (c *Client) ExplorerURL(txid string, currency CryptoCurrency) string {
+	if c.explorers == nil {
+		return ExplorerURL(txid, currency)
+	}
+	return explorerURL(c.explorers, txid, currency)
+}
+
+// explorerURL returns the link of txid with the explorer of c in explorers.
+// Transaction IDs come from the server, so txid is escaped as a path segment.
+func explorerURL(explorers map[CryptoCurrency]string, txid string, c CryptoCurrency) string {
+	base, ok := explorers[c]
+	if !ok || txid == "" {
+		return ""
+	}
+	return base + url.PathEscape(txid)
+}
+
+// ReceivedTxURL returns the explorer link of the deposit transaction.
+func (od *OrderResponse) ReceivedTxURL() string {
+	if od.ReceivedID == nil {
+		return ""
+	}
+	return ExplorerURL(*od.ReceivedID, od.FromCurrency)
+}
+
+// SentTxURL returns the explorer link of the payout transaction.
+func (od *OrderResponse) SentTxURL() string {
+	if od.SentID == nil {
+		return ""
+	}
+	return ExplorerURL(*od.SentID, od.ToCurrency)
+}
+
+// ReceivedTxURL is like OrderResponse.ReceivedTxURL but uses the explorers
+// set with Explorers.
+func (c *Client) ReceivedTxURL(order *OrderResponse) string {
+	if order.ReceivedID == nil {
+		return ""
+	}
+	return c.ExplorerURL(*order.ReceivedID, order.FromCurrency)
+}
+
+// SentTxURL is like OrderResponse.SentTxURL but uses the explorers set with
+// Explorers.
+func (c *Client) SentTxURL(order *OrderResponse) string {
+	if order.SentID == nil {
+		return ""
+	}
+	return c.ExplorerURL(*order.SentID, order.ToCurrency)
+}
@@ -0,0 +1,38 @@
+package goexch
+
+import "testing"
+
+func TestExplorers(t *testing.T) {
+	txid := "abc123"
+	order := &OrderResponse{FromCurrency: Bitcoin, ToCurrency: Monero, ReceivedID: &txid, SentID: &txid}
+
+	explorers := map[CryptoCurrency]string{Bitcoin: "https://blockstream.info/tx/", Monero: ""}
+	c := New("")
+	c.Explorers(explorers)
+	explorers[Bitcoin] = "https://changed.example/tx/"
+
+	if got, want := c.ReceivedTxURL(order), "https://blockstream.info/tx/abc123"; got != want {
+		t.Errorf("overridden explorer: got %q, want %q", got, want)
+	}
+	if got := c.SentTxURL(order); got != "" {
+		t.Errorf("removed explorer: expected no link, got %q", got)
+	}
+	if got, want := c.ExplorerURL(txid, Litecoin), "https://litecoinspace.org/tx/abc123"; got != want {
+		t.Errorf("default explorer: got %q, want %q", got, want)
+	}
+
+	// Other clients and the package-level functions keep the defaults
+	if got, want := order.ReceivedTxURL(), "https://mempool.space/tx/abc123"; got != want {
+		t.Errorf("package default: got %q, want %q", got, want)
+	}
+	if got, want := New("").ReceivedTxURL(order), "https://mempool.space/tx/abc123"; got != want {
+		t.Errorf("other client: got %q, want %q", got, want)
+	}
+}
+
+func TestExplorerURLEscapesTxID(t *testing.T) {
+	got := ExplorerURL("abc/../../evil?x=1#y", Bitcoin)
+	if want := "https://mempool.space/tx/abc%2F..%2F..%2Fevil%3Fx=1%23y"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package goexch
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Endpoints sets base URLs to fail over between, e.g. the clearnet address,
+// an onion address and a mirror. A request that fails with a transport error
+// is retried against the following endpoints; responses with an error status
+// do not fail over. Requests other than GET only fail over if no connection
+// could be made, so an order is never created twice. The endpoint that last
+// worked is tried first, so dead endpoints are not retried on every call.
+func (c *Client) Endpoints(urls []string) {
+	c.baseMu.Lock()
+	defer c.baseMu.Unlock()
+
+	c.endpoints = make([]string, 0, len(urls))
+	for _, u := range urls {
+		if u = strings.TrimSpace(u); u != "" {
+			c.endpoints = append(c.endpoints, u)
+		}
+	}
+	if len(c.endpoints) > 0 {
+		c.baseURL = c.endpoints[0]
+	}
+}
+
+// bases returns the base URLs to try, starting with the current one.
+func (c *Client) bases() []string {
+	c.baseMu.Lock()
+	defer c.baseMu.Unlock()
+
+	bases := make([]string, 0, len(c.endpoints)+1)
+	bases = append(bases, c.baseURL)
+	for _, u := range c.endpoints {
+		if u != c.baseURL {
+			bases = append(bases, u)
+		}
+	}
+	return bases
+}
+
+// useBase makes base the first URL tried by later requests.
+func (c *Client) useBase(base string) {
+	c.baseMu.Lock()
+	defer c.baseMu.Unlock()
+
+	c.baseURL = base
+}
+
+// failoverSafe reports whether a request that failed with err may be sent to
+// another endpoint without risking to repeat its effect.
+func failoverSafe(method string, err error) bool {
+	if method == http.MethodGet {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
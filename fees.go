@@ -0,0 +1,117 @@
+package goexch
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"regexp"
+
+	"github.com/Hyrting/goexch/internal/json"
+)
+
+// jsonNumber matches the number syntax of JSON, which big.Rat.SetString is
+// more lenient than, e.g. accepting "1/3" and "+5".
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// BaseUnits is an amount in the smallest unit of a currency, e.g. satoshis.
+// exch.cx sends it as a JSON number or a string depending on the currency,
+// and it may be fractional, so both forms are decoded into its decimal text.
+type BaseUnits string
+
+// Rat returns the amount, or false if it is unparsable. An empty amount is
+// zero, as the API omits zero fees.
+func (u BaseUnits) Rat() (*big.Rat, bool) {
+	if u == "" {
+		return new(big.Rat), true
+	}
+	return new(big.Rat).SetString(string(u))
+}
+
+// MarshalJSON encodes the amount as a JSON number, or 0 if it is empty.
+func (u BaseUnits) MarshalJSON() ([]byte, error) {
+	if u == "" {
+		return []byte("0"), nil
+	}
+	if !jsonNumber.MatchString(string(u)) {
+		return nil, fmt.Errorf("invalid amount %q", string(u))
+	}
+	return []byte(u), nil
+}
+
+// UnmarshalJSON decodes a JSON number or a string holding one.
+// null and the empty string decode to an empty amount.
+func (u *BaseUnits) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*u = ""
+		return nil
+	}
+
+	text := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	if text == "" {
+		*u = ""
+		return nil
+	}
+	if !jsonNumber.MatchString(text) {
+		return fmt.Errorf("invalid amount %s", data)
+	}
+
+	*u = BaseUnits(text)
+	return nil
+}
+
+// Fees is the fee breakdown of an order. Amounts are in ToCurrency.
+type Fees struct {
+	// ServicePercent is the service fee in percent of the exchanged amount.
+	ServicePercent *big.Rat
+	// ServiceFee is the service fee, nil until the deposit amount is known.
+	ServiceFee *big.Rat
+	// NetworkFee is the network fee of the payout transaction.
+	NetworkFee *big.Rat
+	// TotalFee is ServiceFee plus NetworkFee, nil until ServiceFee is known.
+	TotalFee *big.Rat
+}
+
+// Fees returns the fee breakdown of the order. NetworkFee is converted from
+// the smallest unit of ToCurrency using its decimals, and is nil for unknown
+// currencies or an unparsable fee.
+func (od *OrderResponse) Fees() Fees {
+	var fees Fees
+
+	if info, ok := od.ToCurrency.Info(); ok {
+		if fee, ok := od.NetworkFee.Rat(); ok {
+			fees.NetworkFee = fee.Quo(fee, scale(info.Decimals))
+		}
+	}
+
+	percent, ok := new(big.Rat).SetString(od.SvcFee)
+	if !ok {
+		return fees
+	}
+	fees.ServicePercent = percent
+
+	received, ok := od.ReceivedAmount()
+	if !ok {
+		return fees
+	}
+	rate, ok := new(big.Rat).SetString(od.Rate)
+	if !ok {
+		return fees
+	}
+
+	// Service fee on the exchanged amount, in ToCurrency
+	fees.ServiceFee = new(big.Rat).Mul(received, rate)
+	fees.ServiceFee.Mul(fees.ServiceFee, percent)
+	fees.ServiceFee.Quo(fees.ServiceFee, big.NewRat(100, 1))
+
+	if fees.NetworkFee != nil {
+		fees.TotalFee = new(big.Rat).Add(fees.ServiceFee, fees.NetworkFee)
+	}
+
+	return fees
+}
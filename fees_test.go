@@ -0,0 +1,79 @@
+package goexch
+
+import (
+	"testing"
+
+	"github.com/Hyrting/goexch/internal/json"
+)
+
+func TestBaseUnitsUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    BaseUnits
+		wantErr bool
+	}{
+		{name: "number", data: `1500`, want: "1500"},
+		{name: "fractional number", data: `0.25`, want: "0.25"},
+		{name: "exponent", data: `1e-3`, want: "1e-3"},
+		{name: "string", data: `"1500"`, want: "1500"},
+		{name: "fractional string", data: `"-0.25"`, want: "-0.25"},
+		{name: "null", data: `null`, want: ""},
+		{name: "empty string", data: `""`, want: ""},
+		{name: "fraction", data: `"1/3"`, wantErr: true},
+		{name: "plus sign", data: `"+5"`, wantErr: true},
+		{name: "leading zero", data: `"05"`, wantErr: true},
+		{name: "text", data: `"abc"`, wantErr: true},
+		{name: "bool", data: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var units BaseUnits
+			err := json.Unmarshal([]byte(tt.data), &units)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", units)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if units != tt.want {
+				t.Errorf("got %q, want %q", units, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaseUnitsMarshal(t *testing.T) {
+	tests := []struct {
+		units   BaseUnits
+		want    string
+		wantErr bool
+	}{
+		{units: "", want: "0"},
+		{units: "1500", want: "1500"},
+		{units: "0.25", want: "0.25"},
+		{units: "1/3", wantErr: true},
+		{units: "+5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		data, err := tt.units.MarshalJSON()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %s", tt.units, data)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.units, err)
+			continue
+		}
+		if string(data) != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.units, data, tt.want)
+		}
+	}
+}
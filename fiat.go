@@ -0,0 +1,61 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+	"math/big"
+)
+
+var ErrNoPriceSource = errors.New("no price source configured")
+
+// PriceSource provides fiat prices for currencies. exch.cx has no fiat rates,
+// so an external provider has to be plugged in with Client.PriceSource.
+type PriceSource interface {
+	// Price returns the value of one unit of c in the fiat currency, e.g. "USD".
+	Price(c CryptoCurrency, fiat string) (*big.Rat, error)
+}
+
+// ContextPriceSource is a PriceSource that can be cancelled. The client calls
+// PriceContext instead of Price on sources implementing it.
+type ContextPriceSource interface {
+	PriceSource
+	PriceContext(ctx context.Context, c CryptoCurrency, fiat string) (*big.Rat, error)
+}
+
+// price looks up a price with the configured source.
+func (c *Client) price(ctx context.Context, currency CryptoCurrency, fiat string) (*big.Rat, error) {
+	if c.prices == nil {
+		return nil, ErrNoPriceSource
+	}
+	if source, ok := c.prices.(ContextPriceSource); ok {
+		return source.PriceContext(ctx, currency, fiat)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.prices.Price(currency, fiat)
+}
+
+// PriceSource sets the provider used for fiat conversions.
+func (c *Client) PriceSource(source PriceSource) {
+	c.prices = source
+}
+
+// FiatValue converts amount of currency into the fiat currency.
+func (c *Client) FiatValue(currency CryptoCurrency, amount *big.Rat, fiat string) (*big.Rat, error) {
+	return c.FiatValueContext(context.Background(), currency, amount, fiat)
+}
+
+// FiatValueContext is like FiatValue but uses ctx for the price lookup.
+func (c *Client) FiatValueContext(ctx context.Context, currency CryptoCurrency, amount *big.Rat, fiat string) (*big.Rat, error) {
+	if err := requireAmount("amount", amount); err != nil {
+		return nil, err
+	}
+
+	price, err := c.price(ctx, currency, fiat)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Rat).Mul(amount, price), nil
+}
@@ -0,0 +1,51 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// blockingPrice is a ContextPriceSource answering only once ctx is done.
+type blockingPrice struct{}
+
+func (blockingPrice) Price(CryptoCurrency, string) (*big.Rat, error) {
+	return nil, errors.New("Price should not be called")
+}
+
+func (blockingPrice) PriceContext(ctx context.Context, _ CryptoCurrency, _ string) (*big.Rat, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestPriceSourceContext(t *testing.T) {
+	c := New("")
+	c.PriceSource(blockingPrice{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.FiatValueContext(ctx, Bitcoin, big.NewRat(1, 1), "USD"); !errors.Is(err, context.Canceled) {
+		t.Errorf("FiatValueContext: expected context.Canceled, got %v", err)
+	}
+	if _, err := c.EstimateFromFiatContext(ctx, "USD", big.NewRat(100, 1), Bitcoin, Monero); !errors.Is(err, context.Canceled) {
+		t.Errorf("EstimateFromFiatContext: expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFiatValue(t *testing.T) {
+	c := New("")
+	if _, err := c.FiatValue(Bitcoin, big.NewRat(1, 1), "USD"); !errors.Is(err, ErrNoPriceSource) {
+		t.Fatalf("expected ErrNoPriceSource, got %v", err)
+	}
+
+	c.PriceSource(fixedPrice(50000))
+	value, err := c.FiatValue(Bitcoin, big.NewRat(1, 2), "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value.Cmp(big.NewRat(25000, 1)) != 0 {
+		t.Fatalf("expected 25000, got %s", value.FloatString(2))
+	}
+}
@@ -0,0 +1,61 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrNotAwaitingDeposit = errors.New("order is not awaiting a deposit")
+
+// Fundability tells whether an order can still be funded.
+type Fundability struct {
+	// Fundable is set when a deposit sent now is expected to be exchanged.
+	Fundable bool
+	// Reason explains why the order is not fundable: ErrNotAwaitingDeposit,
+	// ErrRateExpired or ErrNetworkUnavailable, matched with errors.Is. It is
+	// nil for fundable orders.
+	Reason error
+	// Order is the fetched order.
+	Order *OrderResponse
+}
+
+// ValidateOrder checks whether an order can still be funded. exch.cx has no
+// validation endpoint, so the order and the network status are fetched and
+// checked locally. The returned error reports failed requests only; an
+// unfundable order is described by the Fundability.
+func (c *Client) ValidateOrder(id string) (*Fundability, error) {
+	return c.ValidateOrderContext(context.Background(), id)
+}
+
+// ValidateOrderContext is like ValidateOrder but uses ctx for the requests.
+func (c *Client) ValidateOrderContext(ctx context.Context, id string) (*Fundability, error) {
+	order, err := c.GetOrderContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Fundability{Order: order}
+	switch {
+	case order.Phase() != PhaseAwaitingDeposit:
+		result.Reason = fmt.Errorf("%w: state is %s", ErrNotAwaitingDeposit, order.State)
+	case rateExpired(order):
+		result.Reason = ErrRateExpired
+	}
+	if result.Reason != nil {
+		return result, nil
+	}
+
+	statuses, _, err := c.NetworksContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if status, ok := statuses[order.FromCurrency]; ok && !status.Deposit {
+		result.Reason = fmt.Errorf("%w: %s deposits", ErrNetworkUnavailable, order.FromCurrency)
+	} else if status, ok := statuses[order.ToCurrency]; ok && !status.Withdrawal {
+		result.Reason = fmt.Errorf("%w: %s payouts", ErrNetworkUnavailable, order.ToCurrency)
+	}
+
+	result.Fundable = result.Reason == nil
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package goexch
+
+import (
+	"context"
+	"sort"
+)
+
+// SystemHealth summarizes the network statuses reported by exch.cx, e.g. for
+// a /healthz endpoint.
+type SystemHealth struct {
+	// Healthy is set when every network processes deposits and payouts.
+	Healthy bool `json:"healthy"`
+	// Total is the number of networks reported.
+	Total int `json:"total"`
+	// Enabled is the number of networks processing deposits and payouts.
+	Enabled int `json:"enabled"`
+	// DepositsDown lists the networks not processing deposits.
+	DepositsDown []CryptoCurrency `json:"deposits_down"`
+	// WithdrawalsDown lists the networks not processing payouts.
+	WithdrawalsDown []CryptoCurrency `json:"withdrawals_down"`
+	// Unknown lists the networks whose status could not be parsed.
+	Unknown []CryptoCurrency `json:"unknown"`
+}
+
+// SystemHealth fetches the network statuses and summarizes them.
+func (c *Client) SystemHealth() (*SystemHealth, error) {
+	return c.SystemHealthContext(context.Background())
+}
+
+// SystemHealthContext is like SystemHealth but uses ctx for the request.
+func (c *Client) SystemHealthContext(ctx context.Context) (*SystemHealth, error) {
+	statuses, errs, err := c.NetworksContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &SystemHealth{
+		Total:           len(statuses) + len(errs),
+		DepositsDown:    []CryptoCurrency{},
+		WithdrawalsDown: []CryptoCurrency{},
+		Unknown:         []CryptoCurrency{},
+	}
+	for currency, status := range statuses {
+		if !status.Deposit {
+			health.DepositsDown = append(health.DepositsDown, currency)
+		}
+		if !status.Withdrawal {
+			health.WithdrawalsDown = append(health.WithdrawalsDown, currency)
+		}
+		if status.Deposit && status.Withdrawal {
+			health.Enabled++
+		}
+	}
+	for currency := range errs {
+		health.Unknown = append(health.Unknown, currency)
+	}
+
+	for _, list := range [][]CryptoCurrency{health.DepositsDown, health.WithdrawalsDown, health.Unknown} {
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	}
+	health.Healthy = health.Total > 0 && health.Enabled == health.Total
+
+	return health, nil
+}
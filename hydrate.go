@@ -0,0 +1,88 @@
+package goexch
+
+import "context"
+
+// HydrateOrder completes a partial order, e.g. one decoded from a callback
+// of the caller's own notification pipeline, by fetching it with GetOrder.
+// Fields reported by the server take precedence; fields it leaves unset keep
+// the value of partial. partial is not modified. A nil partial or a blank
+// Orderid fails with ErrMissingID.
+func (c *Client) HydrateOrder(ctx context.Context, partial *OrderResponse) (*OrderResponse, error) {
+	if partial == nil {
+		return nil, &ValidationError{Fields: []FieldError{{Field: "partial", Reason: "is required", Err: ErrMissingID}}}
+	}
+	id, err := requireID(partial.Orderid)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := c.GetOrderContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeOrder(partial, order), nil
+}
+
+// mergeOrder returns a copy of server with its unset fields taken from
+// partial.
+func mergeOrder(partial, server *OrderResponse) *OrderResponse {
+	merged := *server
+
+	if merged.Created == 0 {
+		merged.Created = partial.Created
+	}
+	if merged.Expires == 0 {
+		merged.Expires = partial.Expires
+	}
+	if merged.FromAddr == "" {
+		merged.FromAddr = partial.FromAddr
+	}
+	if merged.AmountReceived == nil {
+		merged.AmountReceived = partial.AmountReceived
+	}
+	if merged.FromCurrency == "" {
+		merged.FromCurrency = partial.FromCurrency
+	}
+	if merged.MaxInput == "" {
+		merged.MaxInput = partial.MaxInput
+	}
+	if merged.MinInput == "" {
+		merged.MinInput = partial.MinInput
+	}
+	if merged.NetworkFee == "" {
+		merged.NetworkFee = partial.NetworkFee
+	}
+	if merged.Orderid == "" {
+		merged.Orderid = partial.Orderid
+	}
+	if merged.Rate == "" {
+		merged.Rate = partial.Rate
+	}
+	if merged.RateMode == "" {
+		merged.RateMode = partial.RateMode
+	}
+	if merged.State == "" {
+		merged.State = partial.State
+	}
+	if merged.SvcFee == "" {
+		merged.SvcFee = partial.SvcFee
+	}
+	if merged.ToAddress == "" {
+		merged.ToAddress = partial.ToAddress
+	}
+	if merged.AmountSent == nil {
+		merged.AmountSent = partial.AmountSent
+	}
+	if merged.ToCurrency == "" {
+		merged.ToCurrency = partial.ToCurrency
+	}
+	if merged.ReceivedID == nil {
+		merged.ReceivedID = partial.ReceivedID
+	}
+	if merged.SentID == nil {
+		merged.SentID = partial.SentID
+	}
+
+	return &merged
+}
@@ -0,0 +1,98 @@
+package goexch
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long created orders are remembered by their
+// idempotency key.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyCache remembers created orders by idempotency key, so that a
+// retried Order call returns the original order instead of creating another.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]idempotencyEntry
+	pending map[string]chan struct{} // Closed when a create finishes
+}
+
+type idempotencyEntry struct {
+	result  *CreateOrderResposnse
+	expires time.Time
+}
+
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{
+		ttl:     ttl,
+		entries: make(map[string]idempotencyEntry),
+		pending: make(map[string]chan struct{}),
+	}
+}
+
+// get returns the order created with key, if it has not expired.
+func (ic *idempotencyCache) get(key string) (*CreateOrderResposnse, bool) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	entry, ok := ic.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+
+	result := *entry.result
+	return &result, true
+}
+
+// claim returns the order created with key, or reserves key for a create
+// request if there is none. While key is reserved, other calls wait for the
+// reservation to be released, so concurrent Order calls sharing a key send a
+// single request. release must be called once the create finished, after put
+// if it succeeded.
+func (ic *idempotencyCache) claim(ctx context.Context, key string) (result *CreateOrderResposnse, release func(), err error) {
+	for {
+		if result, ok := ic.get(key); ok {
+			return result, nil, nil
+		}
+
+		ic.mu.Lock()
+		done, busy := ic.pending[key]
+		if !busy {
+			done = make(chan struct{})
+			ic.pending[key] = done
+			ic.mu.Unlock()
+
+			return nil, func() {
+				ic.mu.Lock()
+				delete(ic.pending, key)
+				ic.mu.Unlock()
+				close(done)
+			}, nil
+		}
+		ic.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-done:
+		}
+	}
+}
+
+// put remembers the order created with key and drops expired entries.
+func (ic *idempotencyCache) put(key string, result *CreateOrderResposnse) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range ic.entries {
+		if now.After(entry.expires) {
+			delete(ic.entries, k)
+		}
+	}
+
+	stored := *result
+	ic.entries[key] = idempotencyEntry{result: &stored, expires: now.Add(ic.ttl)}
+}
@@ -0,0 +1,100 @@
+package goexch
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// warningRecorder is a WarningObserver recording the warnings.
+type warningRecorder struct {
+	NopObserver
+
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (w *warningRecorder) ObserveWarning(endpoint, message string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.warnings = append(w.warnings, endpoint+": "+message)
+}
+
+const testETHAddress = "0x52908400098527886E0F7030069857D2E4169EE7"
+
+func TestRetriedCreateIsOneOrder(t *testing.T) {
+	var hits atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"orderid":"o1"}`))
+	})
+	c.Retry(3, time.Millisecond)
+	observer := &warningRecorder{}
+	c.Observer(observer)
+
+	opts := &OrderOptions{IdempotencyKey: "checkout-1"}
+	for i := 0; i < 3; i++ {
+		created, err := c.Order(Bitcoin, Ethereum, testETHAddress, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.OrderID != "o1" {
+			t.Fatalf("expected order o1, got %q", created.OrderID)
+		}
+	}
+
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("expected 2 create requests, got %d", got)
+	}
+	if len(observer.warnings) != 1 {
+		t.Fatalf("expected 1 warning about the retry, got %q", observer.warnings)
+	}
+}
+
+func TestFailedCreateReportingOrderIsNotRetried(t *testing.T) {
+	var hits atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"orderid":"o1"}`))
+	})
+	c.Retry(3, time.Millisecond)
+	observer := &warningRecorder{}
+	c.Observer(observer)
+
+	created, err := c.Order(Bitcoin, Ethereum, testETHAddress, &OrderOptions{IdempotencyKey: "checkout-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.OrderID != "o1" {
+		t.Fatalf("expected order o1, got %q", created.OrderID)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 create request, got %d", got)
+	}
+	if len(observer.warnings) != 0 {
+		t.Fatalf("expected no warnings, got %q", observer.warnings)
+	}
+}
+
+func TestCreateWithoutKeyIsNotRetried(t *testing.T) {
+	var hits atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	c.Retry(3, time.Millisecond)
+
+	if _, err := c.Order(Bitcoin, Ethereum, testETHAddress, nil); err == nil {
+		t.Fatal("expected the create to fail")
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected 1 create request, got %d", got)
+	}
+}
@@ -0,0 +1,72 @@
+package goexch
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// DefaultPriceImpactThreshold is the share of the 24-hour volume, in
+// percent, above which PriceImpact warns.
+const DefaultPriceImpactThreshold = 5
+
+// PriceImpactWarning flags an order that is large relative to the 24-hour
+// volume of its source currency, so its rate may move noticeably.
+type PriceImpactWarning struct {
+	Currency CryptoCurrency
+	// Amount is the intended input.
+	Amount *big.Rat
+	// Volume is the 24-hour volume of Currency.
+	Volume *big.Rat
+	// Percent is Amount as a share of Volume.
+	Percent *big.Rat
+}
+
+func (w *PriceImpactWarning) String() string {
+	return fmt.Sprintf("%s %s is %s%% of the 24h volume", w.Amount.FloatString(8), w.Currency, w.Percent.FloatString(2))
+}
+
+// PriceImpact compares amount of from with its 24-hour volume and returns a
+// warning if it exceeds thresholdPercent of it, or nil otherwise. A
+// non-positive threshold uses DefaultPriceImpactThreshold; a NaN or infinite
+// one fails with a ValidationError.
+func (c *Client) PriceImpact(from CryptoCurrency, amount *big.Rat, thresholdPercent float64) (*PriceImpactWarning, error) {
+	return c.PriceImpactContext(context.Background(), from, amount, thresholdPercent)
+}
+
+// PriceImpactContext is like PriceImpact but uses ctx for the request.
+func (c *Client) PriceImpactContext(ctx context.Context, from CryptoCurrency, amount *big.Rat, thresholdPercent float64) (*PriceImpactWarning, error) {
+	if err := requireAmount("amount", amount); err != nil {
+		return nil, err
+	}
+	if math.IsNaN(thresholdPercent) || math.IsInf(thresholdPercent, 0) {
+		verr := &ValidationError{}
+		verr.add("threshold", fmt.Sprintf("%v is not a finite percent", thresholdPercent), nil)
+		return nil, verr
+	}
+	if thresholdPercent <= 0 {
+		thresholdPercent = DefaultPriceImpactThreshold
+	}
+
+	volume, err := c.VolumeForContext(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := volume.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	// Any order is significant on a market without volume
+	percent := big.NewRat(100, 1)
+	if parsed.Sign() > 0 {
+		percent.Mul(percent, amount)
+		percent.Quo(percent, parsed)
+	}
+	if percent.Cmp(new(big.Rat).SetFloat64(thresholdPercent)) <= 0 {
+		return nil, nil
+	}
+
+	return &PriceImpactWarning{Currency: from, Amount: new(big.Rat).Set(amount), Volume: parsed, Percent: percent}, nil
+}
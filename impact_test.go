@@ -0,0 +1,44 @@
+package goexch
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"net/http"
+	"testing"
+)
+
+func TestPriceImpact(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"BTC":{"volume":"10"}}`))
+	})
+
+	warning, err := c.PriceImpact(Bitcoin, big.NewRat(1, 1), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if warning == nil || warning.Percent.Cmp(big.NewRat(10, 1)) != 0 {
+		t.Fatalf("expected a warning at 10%%, got %v", warning)
+	}
+
+	warning, err = c.PriceImpact(Bitcoin, big.NewRat(1, 1), 20)
+	if err != nil || warning != nil {
+		t.Fatalf("expected no warning below the threshold, got %v, %v", warning, err)
+	}
+}
+
+func TestPriceImpactInvalidInput(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	})
+
+	if _, err := c.PriceImpact(Bitcoin, nil, 0); !errors.Is(err, ErrMissingAmount) {
+		t.Errorf("nil amount: expected ErrMissingAmount, got %v", err)
+	}
+	for _, threshold := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		var verr *ValidationError
+		if _, err := c.PriceImpact(Bitcoin, big.NewRat(1, 1), threshold); !errors.As(err, &verr) {
+			t.Errorf("threshold %v: expected a ValidationError, got %v", threshold, err)
+		}
+	}
+}
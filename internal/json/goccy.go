@@ -0,0 +1,21 @@
+//go:build !stdjson
+
+// Package json selects the JSON implementation used by goexch. By default it
+// is github.com/goccy/go-json; building with the stdjson tag switches to
+// encoding/json.
+package json
+
+import "github.com/goccy/go-json"
+
+// RawMessage is a raw encoded JSON value.
+type RawMessage = json.RawMessage
+
+// Marshal returns the JSON encoding of v.
+func Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal parses the JSON-encoded data and stores the result in v.
+func Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
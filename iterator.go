@@ -0,0 +1,90 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrIteratorDone = errors.New("no more items in iterator")
+
+// Iterator fetches items lazily, a page at a time:
+//
+//	for {
+//		item, err := it.Next(ctx)
+//		if errors.Is(err, ErrIteratorDone) {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+type Iterator[T any] struct {
+	fetch func(ctx context.Context) ([]T, bool, error)
+	page  []T
+	more  bool
+	err   error
+}
+
+// NewIterator returns an Iterator over the pages returned by fetch. fetch
+// reports whether more pages follow; it is called again only once the
+// previous page was consumed.
+func NewIterator[T any](fetch func(ctx context.Context) (items []T, more bool, err error)) *Iterator[T] {
+	return &Iterator[T]{fetch: fetch, more: true}
+}
+
+// Next returns the next item, fetching the next page if needed. It returns
+// ErrIteratorDone after the last item and keeps returning the first error
+// that stopped the iteration.
+func (it *Iterator[T]) Next(ctx context.Context) (T, error) {
+	var zero T
+	for len(it.page) == 0 {
+		if it.err != nil {
+			return zero, it.err
+		}
+		if !it.more {
+			return zero, ErrIteratorDone
+		}
+
+		page, more, err := it.fetch(ctx)
+		if err != nil {
+			it.err = err
+			return zero, err
+		}
+		it.page, it.more = page, more
+	}
+
+	item := it.page[0]
+	it.page = it.page[1:]
+	return item, nil
+}
+
+// Err returns the error that stopped the iteration, or nil if it is still
+// running or ended normally.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Done reports whether Next will not return more items.
+func (it *Iterator[T]) Done() bool {
+	return len(it.page) == 0 && (!it.more || it.err != nil)
+}
+
+// Orders returns an Iterator fetching the orders with the given IDs one at a
+// time. exch.cx has no order history, so the IDs have to be known.
+// Fetching stops at the first failed lookup.
+func (c *Client) Orders(ids []string) *Iterator[*OrderResponse] {
+	remaining := ids
+	return NewIterator(func(ctx context.Context) ([]*OrderResponse, bool, error) {
+		if len(remaining) == 0 {
+			return nil, false, nil
+		}
+
+		order, err := c.GetOrderContext(ctx, remaining[0])
+		if err != nil {
+			return nil, false, err
+		}
+		remaining = remaining[1:]
+		return []*OrderResponse{order}, len(remaining) > 0, nil
+	})
+}
@@ -0,0 +1,52 @@
+package goexch
+
+import (
+	"context"
+	"time"
+)
+
+// Meta describes how a request was served.
+type Meta struct {
+	// StatusCode is the HTTP status of the response, or 0 if none arrived.
+	StatusCode int
+	// Latency is the time the call took, including retries.
+	Latency time.Duration
+	// Cached is set when the response was served from the cache.
+	Cached bool
+	// TokensRemaining is the number of rate limiter tokens left after the
+	// call, or -1 without a rate limiter.
+	TokensRemaining int
+	// RequestID is the X-Request-ID sent with the request, if any.
+	RequestID string
+	// BytesSent and BytesReceived are the sizes of the request and response
+	// as in RequestInfo.
+	BytesSent     int64
+	BytesReceived int64
+	// Date is the server time from the Date header of the response, with
+	// second precision, or the zero time if it was missing.
+	Date time.Time
+}
+
+type metaKey struct{}
+
+// CaptureMeta returns a context that records the metadata of requests made
+// with it into the returned Meta. With several requests, the Meta describes
+// the last one; the context must not be used for concurrent requests.
+func CaptureMeta(ctx context.Context) (context.Context, *Meta) {
+	meta := &Meta{TokensRemaining: -1}
+	return context.WithValue(ctx, metaKey{}, meta), meta
+}
+
+// metaFrom returns the Meta recording requests made with ctx, or nil.
+func metaFrom(ctx context.Context) *Meta {
+	meta, _ := ctx.Value(metaKey{}).(*Meta)
+	return meta
+}
+
+// GetOrderFull is like GetOrderContext but also returns the metadata of the
+// request. The Meta is returned even when the request fails.
+func (c *Client) GetOrderFull(ctx context.Context, id string) (*OrderResponse, *Meta, error) {
+	ctx, meta := CaptureMeta(ctx)
+	order, err := c.GetOrderContext(ctx, id)
+	return order, meta, err
+}
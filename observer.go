@@ -0,0 +1,69 @@
+package goexch
+
+import "time"
+
+// Observer receives metrics about every request sent by the client. It can be
+// backed by Prometheus, expvar or plain logging. Implementations must be safe
+// for concurrent use.
+type Observer interface {
+	// ObserveRequest is called once the request finished. status is 0 when no
+	// response was received.
+	ObserveRequest(endpoint string, status int, dur time.Duration)
+}
+
+// RequestInfo describes a finished request.
+type RequestInfo struct {
+	Endpoint string
+	// Status is 0 when no response was received.
+	Status   int
+	Duration time.Duration
+	// RequestID is the X-Request-ID sent, empty unless Client.RequestIDFunc
+	// is set.
+	RequestID string
+	// Params are the request parameters. Addresses, order IDs and other
+	// sensitive values are redacted unless Client.UnsafeLogging is set.
+	Params map[string]string
+	// BytesSent is the size of the query and body sent, excluding headers.
+	BytesSent int64
+	// BytesReceived is the size of the response body as received, before
+	// decompression.
+	BytesReceived int64
+}
+
+// InfoObserver is an Observer that receives the full RequestInfo. The client
+// calls ObserveRequestInfo instead of ObserveRequest on observers
+// implementing it.
+type InfoObserver interface {
+	Observer
+	ObserveRequestInfo(info RequestInfo)
+}
+
+// WarningObserver is an Observer that is also told about anomalies the client
+// detected, such as an order that was only created by a retry. The client
+// calls ObserveWarning on observers implementing it. Order IDs in messages
+// are redacted unless Client.UnsafeLogging is set.
+type WarningObserver interface {
+	Observer
+	ObserveWarning(endpoint, message string)
+}
+
+// NopObserver is an Observer that discards all observations.
+type NopObserver struct{}
+
+func (NopObserver) ObserveRequest(endpoint string, status int, dur time.Duration) {}
+
+// observe reports info to observer.
+func observe(observer Observer, info RequestInfo) {
+	if o, ok := observer.(InfoObserver); ok {
+		o.ObserveRequestInfo(info)
+		return
+	}
+	observer.ObserveRequest(info.Endpoint, info.Status, info.Duration)
+}
+
+// warn reports a warning to observer if it implements WarningObserver.
+func warn(observer Observer, endpoint, message string) {
+	if o, ok := observer.(WarningObserver); ok {
+		o.ObserveWarning(endpoint, message)
+	}
+}
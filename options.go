@@ -0,0 +1,67 @@
+package goexch
+
+import "math/big"
+
+// OrderOptionsBuilder builds OrderOptions fluently:
+//
+//	opts := NewOrderOptions().RefundTo(addr).Rate(RateFlat).Fee(FeeFast).Aggregate(true).Build()
+type OrderOptionsBuilder struct {
+	opts OrderOptions
+}
+
+// NewOrderOptions returns a builder for OrderOptions.
+func NewOrderOptions() *OrderOptionsBuilder {
+	return &OrderOptionsBuilder{}
+}
+
+// RefundTo sets the refund address.
+func (b *OrderOptionsBuilder) RefundTo(address string) *OrderOptionsBuilder {
+	b.opts.RefundAddress = address
+	return b
+}
+
+// Rate sets the rate mode.
+func (b *OrderOptionsBuilder) Rate(mode string) *OrderOptionsBuilder {
+	b.opts.RateMode = mode
+	return b
+}
+
+// Fee sets the network fee option.
+func (b *OrderOptionsBuilder) Fee(fee string) *OrderOptionsBuilder {
+	b.opts.FeeOption = fee
+	return b
+}
+
+// Referrer sets the referrer ID.
+func (b *OrderOptionsBuilder) Referrer(id string) *OrderOptionsBuilder {
+	b.opts.ReferrerID = id
+	return b
+}
+
+// Aggregate sets BTC aggregation to AggregationYes or AggregationNo.
+func (b *OrderOptionsBuilder) Aggregate(aggregate bool) *OrderOptionsBuilder {
+	if aggregate {
+		b.opts.AggregationMode = AggregationYes
+	} else {
+		b.opts.AggregationMode = AggregationNo
+	}
+	return b
+}
+
+// IdempotencyKey sets the idempotency key.
+func (b *OrderOptionsBuilder) IdempotencyKey(key string) *OrderOptionsBuilder {
+	b.opts.IdempotencyKey = key
+	return b
+}
+
+// MinOutput sets the minimum acceptable output.
+func (b *OrderOptionsBuilder) MinOutput(min *big.Rat) *OrderOptionsBuilder {
+	b.opts.MinOutput = min
+	return b
+}
+
+// Build returns the options. The builder may be reused afterwards.
+func (b *OrderOptionsBuilder) Build() *OrderOptions {
+	opts := b.opts
+	return &opts
+}
@@ -1,50 +1,234 @@
 package goexch
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"time"
 )
 
-// RateLimiter controls the rate of requests.
+// Conservative request budget applied by Client.DefaultRateLimit. exch.cx
+// does not publish a hard limit, so update these if upstream does.
+const (
+	DefaultRateLimitRequests = 60
+	DefaultRateLimitPeriod   = time.Minute
+)
+
+// DefaultEndpointWeights is the number of rate limiter tokens a request to
+// each endpoint costs. Endpoints not listed cost one token. exch.cx does not
+// publish its accounting, so order creation is assumed to be the most
+// expensive call.
+var DefaultEndpointWeights = map[string]int{
+	"create": 2,
+}
+
+// RateLimiter controls the rate of requests with a token bucket. The bucket
+// holds up to max tokens (the burst) and gains one token every interval (the
+// sustained rate), so "10 burst, 1 token per 200ms" is
+// NewRateLimiter(10, 200*time.Millisecond).
 type RateLimiter struct {
 	mu       sync.Mutex
-	tokens   int           // Current number of tokens
-	max      int           // Maximum tokens
-	interval time.Duration // Time to replenish one token
-	last     time.Time     // Last time tokens were added
+	tokens   int              // Current number of tokens
+	max      int              // Maximum tokens
+	interval time.Duration    // Time to replenish one token
+	last     time.Time        // Last time tokens were added
+	now      func() time.Time // Clock, replaced in tests
+
+	// Set for limiters refilled by a background ticker
+	ticker    *time.Ticker
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
-// NewRateLimiter creates a new RateLimiter.
-func NewRateLimiter(max int, interval time.Duration) *RateLimiter {
+// NewRateLimiter creates a new RateLimiter that starts full with burst tokens
+// and refills one token every refillInterval. A non-positive refillInterval
+// refills the bucket immediately.
+func NewRateLimiter(burst int, refillInterval time.Duration) *RateLimiter {
 	return &RateLimiter{
-		tokens:   max,
-		max:      max,
-		interval: interval,
+		tokens:   burst,
+		max:      burst,
+		interval: refillInterval,
 		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+// NewTickingRateLimiter is like NewRateLimiter but refills tokens from a
+// background goroutine driven by a time.Ticker instead of lazily on each
+// call. This keeps the refill steady and cheap for Allow, at the cost of a
+// goroutine that lives until Close is called; use NewRateLimiter when that is
+// not acceptable. A non-positive refillInterval returns a lazy limiter.
+func NewTickingRateLimiter(burst int, refillInterval time.Duration) *RateLimiter {
+	rl := NewRateLimiter(burst, refillInterval)
+	if refillInterval <= 0 {
+		return rl
+	}
+
+	rl.ticker = time.NewTicker(refillInterval)
+	rl.done = make(chan struct{})
+	go rl.refill()
+
+	return rl
+}
+
+// refill adds a token on every tick until the limiter is closed.
+func (rl *RateLimiter) refill() {
+	for {
+		select {
+		case <-rl.done:
+			return
+		case <-rl.ticker.C:
+			rl.mu.Lock()
+			if rl.tokens < rl.max {
+				rl.tokens++
+			}
+			rl.last = rl.now()
+			rl.mu.Unlock()
+		}
+	}
+}
+
+// Close stops the refill goroutine of a ticking limiter. It is a no-op for
+// lazy limiters and safe to call more than once.
+func (rl *RateLimiter) Close() {
+	rl.closeOnce.Do(func() {
+		if rl.ticker != nil {
+			rl.ticker.Stop()
+			close(rl.done)
+		}
+	})
+}
+
+// accrued returns the number of tokens gained since last.
+func (rl *RateLimiter) accrued(now time.Time) int {
+	if rl.ticker != nil {
+		// Refilled by the ticker goroutine
+		return 0
 	}
+	if rl.interval <= 0 {
+		return rl.max
+	}
+	return int(now.Sub(rl.last) / rl.interval)
 }
 
 // Allow checks if a request can proceed.
 func (rl *RateLimiter) Allow() bool {
+	return rl.AllowN(1)
+}
+
+// AllowN is like Allow but takes n tokens, for requests that cost more than
+// one. It fails without taking any tokens if fewer than n are available.
+func (rl *RateLimiter) AllowN(n int) bool {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	// Replenish tokens based on elapsed time
-	now := time.Now()
-	elapsed := now.Sub(rl.last)
-	rl.last = now
+	ok, _ := rl.reserve(rl.now(), n)
+	return ok
+}
+
+// AllowContext is like Allow but fails without taking a token if ctx is
+// done, so a cancelled request does not use up the budget.
+func (rl *RateLimiter) AllowContext(ctx context.Context) bool {
+	return rl.AllowNContext(ctx, 1)
+}
+
+// AllowNContext is like AllowN but fails without taking any tokens if ctx is
+// done.
+func (rl *RateLimiter) AllowNContext(ctx context.Context, n int) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return rl.AllowN(n)
+}
+
+// Wait blocks until a token is available or ctx is done. It returns
+// context.DeadlineExceeded right away when the next token would only arrive
+// after the deadline of ctx, instead of sleeping until then.
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	return rl.WaitN(ctx, 1)
+}
+
+// WaitN is like Wait but takes n tokens. It fails right away if n exceeds the
+// burst of the limiter.
+func (rl *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if n > rl.max {
+		return fmt.Errorf("rate limiter: %d tokens exceed the burst of %d", n, rl.max)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rl.mu.Lock()
+		ok, wait := rl.reserve(rl.now(), n)
+		rl.mu.Unlock()
+
+		if ok {
+			return nil
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return context.DeadlineExceeded
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes n tokens if they are available. Otherwise it returns the
+// time until enough tokens are added. rl.mu must be held.
+func (rl *RateLimiter) reserve(now time.Time, n int) (bool, time.Duration) {
+	// Replenish tokens based on elapsed time. last only advances by the
+	// whole intervals turned into tokens, carrying the remainder over to the
+	// next call, unless the bucket is full
+	accrued := rl.accrued(now)
+	if rl.ticker == nil {
+		if rl.interval <= 0 || rl.tokens+accrued >= rl.max {
+			rl.last = now
+		} else {
+			rl.last = rl.last.Add(time.Duration(accrued) * rl.interval)
+		}
+	}
 
 	// Add tokens for elapsed time
-	rl.tokens += int(elapsed / rl.interval)
+	rl.tokens += accrued
 	if rl.tokens > rl.max {
 		rl.tokens = rl.max
 	}
 
 	// Check if we can allow a request
-	if rl.tokens > 0 {
-		rl.tokens--
-		return true
+	if n <= 0 {
+		return true, 0
+	}
+	if rl.tokens >= n {
+		rl.tokens -= n
+		return true, 0
+	}
+
+	missing := time.Duration(n - rl.tokens)
+	wait := rl.last.Add(missing * rl.interval).Sub(now)
+	if wait < 0 {
+		wait = 0
+	}
+	return false, wait
+}
+
+// Tokens returns the number of tokens currently available, without
+// consuming one.
+func (rl *RateLimiter) Tokens() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	tokens := rl.tokens + rl.accrued(rl.now())
+	if tokens > rl.max {
+		tokens = rl.max
 	}
 
-	return false
+	return tokens
 }
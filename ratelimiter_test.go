@@ -0,0 +1,105 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAllowContextCancelled(t *testing.T) {
+	rl := NewRateLimiter(1, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if rl.AllowContext(ctx) {
+		t.Fatal("expected a cancelled context to be rejected")
+	}
+	if !rl.AllowContext(context.Background()) {
+		t.Fatal("expected the token to be left for the next request")
+	}
+}
+
+// fakeClock returns a clock for rl that only advances when told to.
+func fakeClock(rl *RateLimiter) (advance func(time.Duration)) {
+	now := rl.last
+	rl.now = func() time.Time { return now }
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRefillKeepsRemainder(t *testing.T) {
+	const max = 3
+	rl := NewRateLimiter(max, time.Second)
+	advance := fakeClock(rl)
+
+	// Spaced one interval apart, every call after the burst gets the token
+	// added since the previous one
+	for i := 0; i < max+5; i++ {
+		if !rl.Allow() {
+			t.Fatalf("call %d was rejected", i+1)
+		}
+		advance(time.Second)
+	}
+
+	// Partial intervals add up instead of being dropped
+	rl = NewRateLimiter(1, time.Second)
+	advance = fakeClock(rl)
+	rl.Allow()
+	advance(600 * time.Millisecond)
+	if rl.Allow() {
+		t.Fatal("expected no token after 600ms")
+	}
+	advance(600 * time.Millisecond)
+	if !rl.Allow() {
+		t.Fatal("expected a token after 1.2s")
+	}
+}
+
+func TestBurstThenSteadyRefill(t *testing.T) {
+	rl := NewRateLimiter(10, 200*time.Millisecond)
+	advance := fakeClock(rl)
+
+	for i := 0; i < 10; i++ {
+		if !rl.Allow() {
+			t.Fatalf("burst call %d was rejected", i+1)
+		}
+	}
+	if rl.Allow() {
+		t.Fatal("expected the bucket to be empty after the burst")
+	}
+
+	// One token every 200ms, never more than one banked at a time
+	for i := 0; i < 5; i++ {
+		advance(200 * time.Millisecond)
+		if !rl.Allow() {
+			t.Fatalf("refill %d was rejected", i+1)
+		}
+		if rl.Allow() {
+			t.Fatalf("refill %d added more than one token", i+1)
+		}
+	}
+
+	// An idle period refills up to the burst only
+	advance(time.Hour)
+	if got := rl.Tokens(); got != 10 {
+		t.Fatalf("expected a full bucket of 10 tokens, got %d", got)
+	}
+}
+
+func TestWaitDeadlineBeforeNextToken(t *testing.T) {
+	rl := NewRateLimiter(1, time.Hour)
+	rl.Allow()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := rl.WaitN(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 25*time.Millisecond {
+		t.Fatalf("expected WaitN to fail right away, it took %v", elapsed)
+	}
+}
@@ -0,0 +1,93 @@
+package goexch
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Receipt is a portable record of an order, e.g. for accounting or support
+// tickets. Amounts are formatted with the currency's decimals; fields that
+// are not known yet are empty.
+type Receipt struct {
+	OrderID       string         `json:"order_id"`
+	Pair          string         `json:"pair"`
+	FromCurrency  CryptoCurrency `json:"from_currency"`
+	ToCurrency    CryptoCurrency `json:"to_currency"`
+	State         OrderState     `json:"state"`
+	AmountIn      string         `json:"amount_in,omitempty"`
+	AmountOut     string         `json:"amount_out,omitempty"`
+	Rate          string         `json:"rate"`
+	ServiceFee    string         `json:"service_fee,omitempty"`
+	NetworkFee    string         `json:"network_fee,omitempty"`
+	ReceivedTxID  string         `json:"received_txid,omitempty"`
+	ReceivedTxURL string         `json:"received_tx_url,omitempty"`
+	SentTxID      string         `json:"sent_txid,omitempty"`
+	SentTxURL     string         `json:"sent_tx_url,omitempty"`
+	Created       time.Time      `json:"created"`
+}
+
+// Receipt returns the receipt of the order.
+func (od *OrderResponse) Receipt() *Receipt {
+	fees := od.Fees()
+	receipt := &Receipt{
+		OrderID:       od.Orderid,
+		Pair:          od.Pair().String(),
+		FromCurrency:  od.FromCurrency,
+		ToCurrency:    od.ToCurrency,
+		State:         od.State,
+		Rate:          od.Rate,
+		ServiceFee:    formatOptional(fees.ServiceFee, od.ToCurrency),
+		NetworkFee:    formatOptional(fees.NetworkFee, od.ToCurrency),
+		ReceivedTxID:  optional(od.ReceivedID),
+		ReceivedTxURL: od.ReceivedTxURL(),
+		SentTxID:      optional(od.SentID),
+		SentTxURL:     od.SentTxURL(),
+		Created:       od.Date(),
+	}
+	if received, ok := od.ReceivedAmount(); ok {
+		receipt.AmountIn = formatOptional(received, od.FromCurrency)
+	}
+	if sent, ok := od.SentAmount(); ok {
+		receipt.AmountOut = formatOptional(sent, od.ToCurrency)
+	}
+
+	return receipt
+}
+
+// formatOptional formats amount with the ticker, or returns "" if it is nil.
+func formatOptional(amount *big.Rat, c CryptoCurrency) string {
+	if amount == nil {
+		return ""
+	}
+	return AmountFormat{Ticker: true}.Format(amount, c)
+}
+
+// String returns the receipt as plain text, one fact per line.
+func (r *Receipt) String() string {
+	var b strings.Builder
+
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%-13s %s\n", label+":", value)
+		}
+	}
+	line("Order", r.OrderID)
+	line("Pair", r.Pair)
+	line("State", string(r.State))
+	if !r.Created.IsZero() {
+		line("Created", r.Created.UTC().Format(time.RFC3339))
+	}
+	line("Sent", r.AmountIn)
+	line("Received", r.AmountOut)
+	line("Rate", r.Rate)
+	line("Service fee", r.ServiceFee)
+	line("Network fee", r.NetworkFee)
+	line("Deposit tx", r.ReceivedTxID)
+	line("Deposit URL", r.ReceivedTxURL)
+	line("Payout tx", r.SentTxID)
+	line("Payout URL", r.SentTxURL)
+
+	return b.String()
+}
@@ -0,0 +1,232 @@
+package goexch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/Hyrting/goexch/internal/json"
+)
+
+// scrubbed replaces the sensitive fields in recorded queries and responses,
+// so recordings can be shared without leaking order IDs, addresses or
+// transactions.
+const scrubbed = "REDACTED"
+
+// interaction is a recorded request and its response.
+type interaction struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query"`
+	Status int    `json:"status"`
+	Body   string `json:"body"`
+}
+
+// Recorder makes the client save every request and response to the JSON file
+// at path, for replaying them later with Replay. Order IDs, addresses and
+// transaction IDs are scrubbed from the recording, so replayed orders report
+// "REDACTED" IDs. It wraps the transport of the current HTTP client, so
+// call it after Client.
+func (c *Client) Recorder(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	next := c.client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	client := *c.client
+	client.Transport = &recordingTransport{path: path, next: next}
+	c.client = &client
+
+	return nil
+}
+
+// Replay makes the client answer requests from a recording made with
+// Recorder instead of sending them. Requests are matched by method, path and
+// query; repeated requests are answered with the recorded responses in order,
+// the last one being repeated. Unmatched requests fail.
+func (c *Client) Replay(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var interactions []interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return fmt.Errorf("invalid recording: %v", err)
+	}
+
+	replay := &replayTransport{responses: make(map[string][]interaction)}
+	for _, recorded := range interactions {
+		key := recorded.Method + " " + recorded.Path + "?" + recorded.Query
+		replay.responses[key] = append(replay.responses[key], recorded)
+	}
+
+	client := *c.client
+	client.Transport = replay
+	c.client = &client
+
+	return nil
+}
+
+// recordingTransport records interactions before handing them to the client.
+type recordingTransport struct {
+	mu           sync.Mutex
+	path         string
+	next         http.RoundTripper
+	interactions []interaction
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	query, err := requestQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	// Store the decompressed body, so recordings stay readable
+	reader, err := decodeBody(res)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	res.Header.Del("Content-Encoding")
+	res.ContentLength = int64(len(body))
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.interactions = append(t.interactions, interaction{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  query,
+		Status: res.StatusCode,
+		Body:   string(scrubBody(body)),
+	})
+
+	data, err := json.Marshal(t.interactions)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(t.path, data, 0o600); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// replayTransport answers requests from recorded interactions.
+type replayTransport struct {
+	mu        sync.Mutex
+	responses map[string][]interaction
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	query, err := requestQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	key := req.Method + " " + req.URL.Path + "?" + query
+
+	t.mu.Lock()
+	recorded, ok := t.responses[key]
+	if ok && len(recorded) > 1 {
+		t.responses[key] = recorded[1:]
+	}
+	t.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no recorded response for %s", key)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded[0].Status, http.StatusText(recorded[0].Status)),
+		StatusCode:    recorded[0].Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewBufferString(recorded[0].Body)),
+		ContentLength: int64(len(recorded[0].Body)),
+		Request:       req,
+	}, nil
+}
+
+// requestQuery returns the scrubbed parameters of req, read from the query or
+// a form body. A form body is restored for sending.
+func requestQuery(req *http.Request) (string, error) {
+	values := req.URL.Query()
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(data))
+
+		form, err := url.ParseQuery(string(data))
+		if err != nil {
+			return "", err
+		}
+		for key, value := range form {
+			values[key] = value
+		}
+	}
+
+	for field := range values {
+		if sensitive(field) {
+			values.Set(field, scrubbed)
+		}
+	}
+
+	return values.Encode(), nil
+}
+
+// scrubBody replaces the scrubbed fields of a JSON object body. Other bodies
+// are returned unchanged.
+func scrubBody(body []byte) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+
+	changed := false
+	for field, value := range fields {
+		if sensitive(field) && string(value) != "null" {
+			fields[field] = json.RawMessage(`"` + scrubbed + `"`)
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+
+	scrubbedBody, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return scrubbedBody
+}
@@ -0,0 +1,51 @@
+package goexch
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorderScrubsSensitiveFields(t *testing.T) {
+	const (
+		orderID = "ee4f1f3a8d7b2c90"
+		address = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+	)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"orderid":"` + orderID + `","from_addr":"` + address + `","state":"CREATED"}`))
+	})
+	path := filepath.Join(t.TempDir(), "recording.json")
+	if err := c.Recorder(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.GetOrder(orderID); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{orderID, address} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("recording contains %q: %s", leaked, data)
+		}
+	}
+
+	replay := New("")
+	replay.Endpoints(c.bases())
+	replay.NoRateLimit()
+	if err := replay.Replay(path); err != nil {
+		t.Fatal(err)
+	}
+	order, err := replay.GetOrder(orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Orderid != scrubbed || order.State != StateCreated {
+		t.Fatalf("unexpected replayed order %+v", order)
+	}
+}
@@ -0,0 +1,59 @@
+package goexch
+
+// sensitiveParams are the request parameters and response fields redacted
+// before they reach observers or recordings. Knowing an order ID is enough to
+// look the order up, so it is treated as sensitive too.
+var sensitiveParams = map[string]bool{
+	"orderid":                 true,
+	"to_address":              true,
+	"refund_address":          true,
+	"from_addr":               true,
+	"transaction_id_received": true,
+	"transaction_id_sent":     true,
+	"api_key":                 true,
+}
+
+// sensitive reports whether a parameter or response field must be redacted.
+func sensitive(field string) bool {
+	return sensitiveParams[field]
+}
+
+// UnsafeLogging makes observers receive request parameters unredacted. It is
+// meant for debugging only, as addresses and order IDs end up in logs.
+func (c *Client) UnsafeLogging(enabled bool) {
+	c.unsafeLogs = enabled
+}
+
+// Redact shortens a sensitive value to its first and last four characters,
+// e.g. "bc1q…5mdq". Values of up to eight characters are masked entirely.
+func Redact(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 8 {
+		return "****"
+	}
+	return string(runes[:4]) + "…" + string(runes[len(runes)-4:])
+}
+
+// redact returns value redacted for observers unless UnsafeLogging is set.
+func (c *Client) redact(value string) string {
+	if c.unsafeLogs {
+		return value
+	}
+	return Redact(value)
+}
+
+// logParams returns a copy of params safe to hand to observers.
+func (c *Client) logParams(params map[string]string) map[string]string {
+	if len(params) == 0 {
+		return nil
+	}
+
+	logged := make(map[string]string, len(params))
+	for key, value := range params {
+		if sensitive(key) {
+			value = c.redact(value)
+		}
+		logged[key] = value
+	}
+	return logged
+}
@@ -0,0 +1,78 @@
+package goexch
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// logRecorder is an InfoObserver and WarningObserver recording everything
+// handed to it as text.
+type logRecorder struct {
+	NopObserver
+
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *logRecorder) ObserveRequestInfo(info RequestInfo) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, value := range info.Params {
+		l.logs = append(l.logs, key+"="+value)
+	}
+}
+
+func (l *logRecorder) ObserveWarning(endpoint, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.logs = append(l.logs, endpoint+": "+message)
+}
+
+func (l *logRecorder) text() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return strings.Join(l.logs, "\n")
+}
+
+func TestObserversGetRedactedValues(t *testing.T) {
+	const orderID = "ee4f1f3a8d7b2c90"
+
+	for _, unsafe := range []bool{false, true} {
+		var hits atomic.Int32
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/create" && hits.Add(1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte(`{"orderid":"` + orderID + `","state":"CREATED"}`))
+		})
+		c.Retry(2, time.Millisecond)
+		c.UnsafeLogging(unsafe)
+		observer := &logRecorder{}
+		c.Observer(observer)
+
+		if _, err := c.Order(Bitcoin, Ethereum, testETHAddress, &OrderOptions{IdempotencyKey: "checkout-1"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.GetOrder(orderID); err != nil {
+			t.Fatal(err)
+		}
+
+		logs := observer.text()
+		if !strings.Contains(logs, "create: order ") {
+			t.Fatalf("expected a create warning, got %q", logs)
+		}
+		for _, value := range []string{orderID, testETHAddress} {
+			if leaked := strings.Contains(logs, value); leaked != unsafe {
+				t.Errorf("UnsafeLogging(%v): logs contain %q: %v\n%s", unsafe, value, leaked, logs)
+			}
+		}
+	}
+}
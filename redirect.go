@@ -0,0 +1,52 @@
+package goexch
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrRedirectNotAllowed = errors.New("redirect not allowed by the redirect policy")
+
+// RedirectPolicy decides which HTTP redirects the client follows.
+type RedirectPolicy int
+
+const (
+	// RedirectSameHost follows redirects to the host of the original request
+	// only. It is the default of the client created by New, so a redirect
+	// cannot send order parameters such as addresses to an unexpected host.
+	// An HTTP client set with Client does not have it.
+	RedirectSameHost RedirectPolicy = iota
+	// RedirectNone follows no redirects.
+	RedirectNone
+	// RedirectFollow follows all redirects, like http.Client does by default.
+	RedirectFollow
+)
+
+// RedirectPolicy sets which redirects are followed. Disallowed redirects fail
+// with ErrRedirectNotAllowed. It applies to a copy of the current HTTP
+// client, leaving a client passed to Client unchanged, so call it after
+// Client.
+func (c *Client) RedirectPolicy(policy RedirectPolicy) {
+	client := *c.client
+	client.CheckRedirect = policy.check
+	c.client = &client
+}
+
+// check implements http.Client.CheckRedirect for the policy.
+func (p RedirectPolicy) check(req *http.Request, via []*http.Request) error {
+	switch p {
+	case RedirectNone:
+		return fmt.Errorf("%w: to %s", ErrRedirectNotAllowed, req.URL.Host)
+	case RedirectSameHost:
+		if req.URL.Host != via[0].URL.Host {
+			return fmt.Errorf("%w: to %s", ErrRedirectNotAllowed, req.URL.Host)
+		}
+	}
+
+	// Same limit as the default policy of http.Client
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package goexch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectToOtherHostRejected(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the redirect to another host was followed")
+	}))
+	defer other.Close()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+r.URL.Path, http.StatusFound)
+	})
+
+	if _, err := c.Status(); !errors.Is(err, ErrRedirectNotAllowed) {
+		t.Fatalf("expected ErrRedirectNotAllowed, got %v", err)
+	}
+}
+
+func TestRedirectPolicyKeepsCallerClient(t *testing.T) {
+	own := &http.Client{}
+	c := New("")
+	c.Client(own)
+	c.RedirectPolicy(RedirectNone)
+
+	if own.CheckRedirect != nil {
+		t.Fatal("RedirectPolicy changed the client passed to Client")
+	}
+	if c.client == own || c.client.CheckRedirect == nil {
+		t.Fatal("RedirectPolicy did not apply to a copy of the client")
+	}
+}
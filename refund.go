@@ -0,0 +1,40 @@
+package goexch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RefundAddressProvider supplies refund addresses, e.g. from an HD wallet or
+// a static map, so every order is refundable by default.
+type RefundAddressProvider interface {
+	// RefundAddress returns an address on the chain of c.
+	RefundAddress(c CryptoCurrency) (string, error)
+}
+
+// RefundAddressProvider sets the provider Order uses to fill in the refund
+// address of orders created without one.
+func (c *Client) RefundAddressProvider(provider RefundAddressProvider) {
+	c.refunds = provider
+}
+
+// withRefundAddress returns opts with the refund address filled in from the
+// provider if it is not set. opts is not modified.
+func (c *Client) withRefundAddress(from CryptoCurrency, opts *OrderOptions) (*OrderOptions, error) {
+	if c.refunds == nil || (opts != nil && strings.TrimSpace(opts.RefundAddress) != "") {
+		return opts, nil
+	}
+
+	address, err := c.refunds.RefundAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("refund address provider: %w", err)
+	}
+
+	filled := OrderOptions{}
+	if opts != nil {
+		filled = *opts
+	}
+	filled.RefundAddress = address
+
+	return &filled, nil
+}
@@ -0,0 +1,124 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+)
+
+// retryablePaths lists the idempotent endpoints that are safe to retry.
+var retryablePaths = map[string]bool{
+	"volume": true,
+	"status": true,
+	"order":  true,
+	"rates":  true,
+}
+
+// retryPolicy retries requests that failed in transport or with a 5xx
+// status, backing off exponentially between attempts.
+type retryPolicy struct {
+	max      int           // Maximum number of retries
+	backoff  time.Duration // Delay before the first retry, doubled each time
+	maxDelay time.Duration // Cap of a single delay, zero for none
+	budget   time.Duration // Cap of the total time spent, zero for none
+}
+
+// Retry retries idempotent lookups (Volume, Status, Rates, GetOrder) up to
+// max times on transport errors and 5xx responses, waiting backoff before the
+// first retry and doubling it after each. Client errors such as
+// ErrOrderNotFound are never retried. A max below 1 disables retries.
+//
+// Order is only retried when OrderOptions.IdempotencyKey is set. exch.cx has
+// no server-side idempotency, and a 5xx or a dropped connection may follow an
+// order that was created, so a retry can create a second one. A failed
+// response that still reports an order ID ends the retries with that order,
+// and an order created by a retry is reported to a WarningObserver. Once an
+// attempt succeeded, later and concurrent calls with the same key return
+// that order without a request.
+func (c *Client) Retry(max int, backoff time.Duration) {
+	p := c.retryPolicy()
+	p.max, p.backoff = max, backoff
+}
+
+// RetryBackoffCap caps a single delay between retries at max, zero meaning
+// no cap.
+func (c *Client) RetryBackoffCap(max time.Duration) {
+	c.retryPolicy().maxDelay = max
+}
+
+// RetryBudget bounds the total time a call spends retrying, including the
+// delays. Once the next delay would exceed the budget, the last error is
+// returned. Zero means no budget.
+func (c *Client) RetryBudget(total time.Duration) {
+	c.retryPolicy().budget = total
+}
+
+// retryPolicy returns the retry policy, creating a disabled one if needed.
+func (c *Client) retryPolicy() *retryPolicy {
+	if c.retry == nil {
+		c.retry = &retryPolicy{}
+	}
+	return c.retry
+}
+
+// enabled reports whether the policy retries at all.
+func (p *retryPolicy) enabled() bool {
+	return p != nil && p.max >= 1
+}
+
+// delay returns the wait before the given retry attempt.
+func (p *retryPolicy) delay(attempt int) time.Duration {
+	delay := p.backoff
+	// Stop doubling before the delay overflows, so it saturates instead of
+	// wrapping around to a negative value
+	for i := 0; i < attempt && delay > 0 && delay <= math.MaxInt64/2; i++ {
+		delay *= 2
+	}
+	if p.maxDelay > 0 && delay > p.maxDelay {
+		delay = p.maxDelay
+	}
+	return delay
+}
+
+// do calls send until it succeeds, fails permanently or retries run out.
+func (p *retryPolicy) do(ctx context.Context, send func() (int, []byte, error)) (int, []byte, error) {
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		statusCode, body, err := send()
+		if attempt >= p.max || !retryable(ctx, statusCode, err) {
+			return statusCode, body, err
+		}
+
+		delay := p.delay(attempt)
+		if p.budget > 0 && time.Since(start)+delay > p.budget {
+			return statusCode, body, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return statusCode, body, err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether a failed attempt may succeed when repeated.
+func retryable(ctx context.Context, statusCode int, err error) bool {
+	// Maintenance outlasts any backoff
+	if ctx.Err() != nil || errors.Is(err, ErrMaintenance) {
+		return false
+	}
+
+	var transportErr *TransportError
+	var readErr *ReadError
+	if errors.As(err, &transportErr) || errors.As(err, &readErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && statusCode >= http.StatusInternalServerError
+}
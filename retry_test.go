@@ -0,0 +1,40 @@
+package goexch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxDelay time.Duration
+		attempt  int
+		want     time.Duration
+	}{
+		{name: "first", attempt: 0, want: time.Second},
+		{name: "doubled", attempt: 3, want: 8 * time.Second},
+		{name: "capped", maxDelay: 5 * time.Second, attempt: 3, want: 5 * time.Second},
+		{name: "capped without overflow", maxDelay: time.Minute, attempt: 100, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &retryPolicy{max: 1, backoff: time.Second, maxDelay: tt.maxDelay}
+			if got := p.delay(tt.attempt); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// Without a cap, the delay saturates instead of overflowing
+	p := &retryPolicy{max: 1, backoff: time.Second}
+	var previous time.Duration
+	for attempt := 0; attempt < 200; attempt++ {
+		got := p.delay(attempt)
+		if got < previous {
+			t.Fatalf("attempt %d: delay dropped from %v to %v", attempt, previous, got)
+		}
+		previous = got
+	}
+}
@@ -0,0 +1,180 @@
+package goexch
+
+import (
+	"context"
+	"fmt"
+)
+
+// OrderState is the state of an order as reported by the API. The API does
+// not report deposit confirmation counts; StateConfirmingInput only signals
+// that confirmations are still pending.
+type OrderState string
+
+const (
+	StateCreated          OrderState = "CREATED"
+	StateCancelled        OrderState = "CANCELLED"
+	StateAwaitingInput    OrderState = "AWAITING_INPUT"
+	StateConfirmingInput  OrderState = "CONFIRMING_INPUT"
+	StateExchanging       OrderState = "EXCHANGING"
+	StateConfirmingSend   OrderState = "CONFIRMING_SEND"
+	StateComplete         OrderState = "COMPLETE"
+	StateRefundRequest    OrderState = "REFUND_REQUEST"
+	StateRefundPending    OrderState = "REFUND_PENDING"
+	StateConfirmingRefund OrderState = "CONFIRMING_REFUND"
+	StateRefunded         OrderState = "REFUNDED"
+	StateToAddressInvalid OrderState = "TO_ADDRESS_INVALID"
+)
+
+// Phase is a coarse grouping of order states for progress indicators.
+type Phase int
+
+const (
+	PhaseUnknown Phase = iota
+	PhaseAwaitingDeposit
+	PhaseProcessing
+	PhaseDone
+	PhaseFailed
+	PhaseNeedsAction
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseAwaitingDeposit:
+		return "AwaitingDeposit"
+	case PhaseProcessing:
+		return "Processing"
+	case PhaseDone:
+		return "Done"
+	case PhaseFailed:
+		return "Failed"
+	case PhaseNeedsAction:
+		return "NeedsAction"
+	}
+	return "Unknown"
+}
+
+// Phase maps the order state to a coarse phase:
+//
+//	CREATED, AWAITING_INPUT                    -> PhaseAwaitingDeposit
+//	CONFIRMING_INPUT, EXCHANGING,
+//	CONFIRMING_SEND, REFUND_PENDING,
+//	CONFIRMING_REFUND                          -> PhaseProcessing
+//	COMPLETE                                   -> PhaseDone
+//	CANCELLED, REFUNDED                        -> PhaseFailed
+//	REFUND_REQUEST, TO_ADDRESS_INVALID         -> PhaseNeedsAction
+//
+// Any other state maps to PhaseUnknown.
+func (od *OrderResponse) Phase() Phase {
+	switch od.State {
+	case StateCreated, StateAwaitingInput:
+		return PhaseAwaitingDeposit
+	case StateConfirmingInput, StateExchanging, StateConfirmingSend, StateRefundPending, StateConfirmingRefund:
+		return PhaseProcessing
+	case StateComplete:
+		return PhaseDone
+	case StateCancelled, StateRefunded:
+		return PhaseFailed
+	case StateRefundRequest, StateToAddressInvalid:
+		return PhaseNeedsAction
+	}
+	return PhaseUnknown
+}
+
+// Action is an order operation exposed by the client.
+type Action string
+
+const (
+	ActionRefund            Action = "refund"
+	ActionConfirmRefund     Action = "confirm_refund"
+	ActionRevalidateAddress Action = "revalidate_address"
+	ActionRemove            Action = "remove"
+)
+
+// actionStates lists the states in which each action is accepted.
+var actionStates = map[Action][]OrderState{
+	ActionRefund:            {StateRefundRequest},
+	ActionConfirmRefund:     {StateRefundRequest, StateRefundPending},
+	ActionRevalidateAddress: {StateToAddressInvalid},
+	ActionRemove:            {StateCancelled, StateComplete, StateRefunded},
+}
+
+// AllowedActions returns the actions that can be performed on the order in
+// its current state. The matching client methods fetch the order and check
+// this before calling the API, failing with ErrActionNotAllowed.
+func (od *OrderResponse) AllowedActions() []Action {
+	var actions []Action
+	for _, action := range []Action{ActionRefund, ActionConfirmRefund, ActionRevalidateAddress, ActionRemove} {
+		if od.Allows(action) {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// Allows reports whether action can be performed on the order in its current
+// state.
+func (od *OrderResponse) Allows(action Action) bool {
+	for _, state := range actionStates[action] {
+		if od.State == state {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAction returns ErrActionNotAllowed if the order does not allow action.
+func (od *OrderResponse) checkAction(action Action) error {
+	if !od.Allows(action) {
+		return fmt.Errorf("%w: %s in state %s", ErrActionNotAllowed, action, od.State)
+	}
+	return nil
+}
+
+// PendingAction is an order that needs user intervention.
+type PendingAction struct {
+	Order *OrderResponse
+	// Actions are the actions that resolve the order's state.
+	Actions []Action
+	// Reason describes what is needed, e.g. "payout address is invalid".
+	Reason string
+}
+
+// pendingReasons describes why orders in each PhaseNeedsAction state need
+// intervention.
+var pendingReasons = map[OrderState]string{
+	StateRefundRequest:    "exchange failed, a refund has to be requested or confirmed with a refund address",
+	StateToAddressInvalid: "payout address is invalid, a new one has to be supplied",
+}
+
+// PendingActions fetches the given orders and returns those needing user
+// intervention, i.e. in PhaseNeedsAction, sorted like ids. Orders that could
+// not be fetched are reported in the error map.
+func (c *Client) PendingActions(ids []string) ([]PendingAction, map[string]error) {
+	return c.PendingActionsContext(context.Background(), ids)
+}
+
+// PendingActionsContext is like PendingActions but uses ctx for the requests.
+func (c *Client) PendingActionsContext(ctx context.Context, ids []string) ([]PendingAction, map[string]error) {
+	orders, errs := c.GetOrdersContext(ctx, ids)
+
+	var pending []PendingAction
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		order, ok := orders[id]
+		if !ok || seen[id] || order.Phase() != PhaseNeedsAction {
+			continue
+		}
+		seen[id] = true
+
+		var actions []Action
+		for _, action := range order.AllowedActions() {
+			// Removing is not a resolution
+			if action != ActionRemove {
+				actions = append(actions, action)
+			}
+		}
+		pending = append(pending, PendingAction{Order: order, Actions: actions, Reason: pendingReasons[order.State]})
+	}
+
+	return pending, errs
+}
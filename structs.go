@@ -1,6 +1,11 @@
 package goexch
 
-import "time"
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
 
 type CryptoCurrency string
 
@@ -16,22 +21,133 @@ const (
 	Dai              CryptoCurrency = "DAI"
 )
 
+// Rate types of an order, for OrderOptions.RateMode.
+const (
+	// RateFlat locks the rate at order creation.
+	RateFlat = "flat"
+	// RateDynamic follows the market until the deposit is exchanged.
+	RateDynamic = "dynamic"
+)
+
+// Network fee options of an order's payout, for OrderOptions.FeeOption.
+const (
+	FeeSlow   = "s"
+	FeeMedium = "m"
+	FeeFast   = "f"
+)
+
+// Aggregation is the BTC aggregation preference of an order.
+type Aggregation int
+
+const (
+	// AggregationDefault leaves the choice to exch.cx.
+	AggregationDefault Aggregation = iota
+	// AggregationYes aggregates inputs on receive and send.
+	AggregationYes
+	// AggregationNo mixes inputs.
+	AggregationNo
+)
+
 // CreateOrderOptional holds optional parameters for creating an order.
+// exch.cx orders are created without an amount, neither a fixed input nor a
+// fixed output (to_amount): any deposit between MinInput and MaxInput is
+// exchanged at the rate. Use Estimate to work out the deposit for a wanted
+// output.
 type OrderOptions struct {
 	// RefundAddress is the address for refunds in case of a failed exchange (Optional; used in REFUND_REQUEST state).
 	RefundAddress string `json:"refund_address,omitempty"`
 	// RateMode specifies the rate type, either "flat" or "dynamic" (Optional; default is "dynamic").
 	RateMode string `json:"rate_mode,omitempty"`
-	// ReferrerID is an identifier for referrals (Optional).
+	// ReferrerID is an identifier for referrals (Optional). Referral earnings
+	// are not exposed by the API and have to be checked on exch.cx.
 	ReferrerID string `json:"ref,omitempty"`
 	// FeeOption specifies the network fee option: "s" for slow, "m" for medium, "f" for quick (Optional; default is "f").
 	FeeOption string `json:"fee_option,omitempty"`
 	// Aggregation indicates BTC aggregation preference: true for aggregated (receive/send), false for mixed, and omitted for default behavior (Optional).
+	//
+	// Deprecated: use AggregationMode, which takes precedence when set.
 	Aggregation *bool `json:"aggregation,omitempty"`
+	// AggregationMode selects the BTC aggregation behavior (Optional; default is AggregationDefault).
+	AggregationMode Aggregation `json:"-"`
+	// Payouts splits the output across several addresses. exch.cx does not
+	// support split payouts, so Order rejects options that set it.
+	Payouts []Payout `json:"-"`
+	// MinOutput is the smallest acceptable output in the target currency
+	// (Optional). exch.cx has no minimum output parameter, so it is only
+	// enforced by OrderAndWait, see ErrSlippageExceeded.
+	MinOutput *big.Rat `json:"-"`
+	// IdempotencyKey makes retried Order calls with the same key return the
+	// originally created order (Optional). Concurrent calls with the same key
+	// wait for the first one, and failed creates are retried per
+	// Client.Retry. exch.cx has no idempotency support, so the key is only
+	// remembered by the client for its idempotency TTL.
+	IdempotencyKey string `json:"-"`
+}
+
+// Validate checks the options for invalid values and conflicting settings,
+// returning a *ValidationError describing all of them. Checks that depend on
+// the currencies, such as the refund address format, are left to Order. A
+// nil OrderOptions is valid.
+func (o *OrderOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+
+	verr := &ValidationError{}
+	if o.RefundAddress != "" && strings.TrimSpace(o.RefundAddress) == "" {
+		verr.add("refund_address", "is blank", ErrMissingAddress)
+	}
+	switch o.RateMode {
+	case "", RateFlat, RateDynamic:
+	default:
+		verr.add("rate_mode", fmt.Sprintf("%q is not a rate mode", o.RateMode), nil)
+	}
+	switch o.FeeOption {
+	case "", FeeSlow, FeeMedium, FeeFast:
+	default:
+		verr.add("fee_option", fmt.Sprintf("%q is not a fee option", o.FeeOption), nil)
+	}
+	switch o.AggregationMode {
+	case AggregationDefault, AggregationYes, AggregationNo:
+	default:
+		verr.add("aggregation", fmt.Sprintf("%d is not an aggregation mode", o.AggregationMode), nil)
+	}
+	if len(o.Payouts) > 0 {
+		verr.add("payouts", "are not supported by exch.cx", nil)
+	}
+	if o.MinOutput != nil {
+		if o.MinOutput.Sign() <= 0 {
+			verr.add("min_output", "must be positive", nil)
+		}
+		// A locked rate cannot slip
+		if o.RateMode == RateFlat {
+			verr.add("min_output", "cannot be combined with RateFlat", nil)
+		}
+	}
+
+	return verr.err()
+}
+
+// Payout is one destination of a split payout.
+type Payout struct {
+	Address string
+	// Weight is the share of the output sent to Address.
+	Weight int
 }
 
 type CreateOrderResposnse struct {
 	OrderID string `json:"orderid"`
+	// Warnings lists caveats of the created order that callers may want to
+	// show to users. exch.cx does not send warnings, so they are derived
+	// from the order options by Order.
+	Warnings []string `json:"warnings,omitempty"`
+	// DryRun is set when the response was synthesized in dry-run mode.
+	DryRun bool `json:"-"`
+}
+
+// HasWarnings reports whether the order was created with warnings.
+func (r *CreateOrderResposnse) HasWarnings() bool {
+	return len(r.Warnings) > 0
 }
 
 type GetVolumeResponse struct {
@@ -45,23 +161,84 @@ type GetVolumeResponse struct {
 	Usdt     *Volume `json:"USDT"`
 	Monero   *Volume `json:"XMR"`
 }
+
+// volume returns the entry for the given currency, or nil if absent.
+func (v *GetVolumeResponse) volume(c CryptoCurrency) *Volume {
+	if v == nil {
+		return nil
+	}
+
+	switch c {
+	case Bitcoin:
+		return v.Bitcoin
+	case BitcoinLightning:
+		return v.Btcln
+	case Dai:
+		return v.Dai
+	case Dash:
+		return v.Dash
+	case Ethereum:
+		return v.Eth
+	case Litecoin:
+		return v.Litecoin
+	case USDCoinErc20:
+		return v.Usdc
+	case TetherErc20:
+		return v.Usdt
+	case Monero:
+		return v.Monero
+	}
+
+	return nil
+}
+
+// Get returns the volume of the given currency, or false if the response has
+// none.
+func (v *GetVolumeResponse) Get(c CryptoCurrency) (*Volume, bool) {
+	volume := v.volume(c)
+	return volume, volume != nil
+}
+
+// ParsedVolume returns the volume of the given currency as a number, or false
+// if the response has none or it is malformed. Use Get and Volume.Parse to
+// tell both cases apart.
+func (v *GetVolumeResponse) ParsedVolume(c CryptoCurrency) (*big.Rat, bool) {
+	volume, ok := v.Get(c)
+	if !ok {
+		return nil, false
+	}
+
+	parsed, err := volume.Parse()
+	return parsed, err == nil
+}
+
 type Volume struct {
 	Volume string `json:"volume"`
 }
 
+// Parse returns the volume as a number.
+func (v *Volume) Parse() (*big.Rat, error) {
+	parsed, ok := new(big.Rat).SetString(v.Volume)
+	if !ok {
+		return nil, fmt.Errorf("invalid volume %q", v.Volume)
+	}
+	return parsed, nil
+}
+
 type OrderResponse struct {
 	Created        int            `json:"created"`
+	Expires        int            `json:"expires"`
 	FromAddr       string         `json:"from_addr"`
 	AmountReceived *string        `json:"from_amount_received"`
 	FromCurrency   CryptoCurrency `json:"from_currency"`
 	MaxInput       string         `json:"max_input"`
 	MinInput       string         `json:"min_input"`
-	NetworkFee     int            `json:"network_fee"`
+	NetworkFee     BaseUnits      `json:"network_fee"` // Payout network fee in the smallest unit of ToCurrency (e.g. satoshis)
 	Orderid        string         `json:"orderid"`
 	Rate           string         `json:"rate"`
 	RateMode       string         `json:"rate_mode"`
-	State          string         `json:"state"`
-	SvcFee         string         `json:"svc_fee"`
+	State          OrderState     `json:"state"`
+	SvcFee         string         `json:"svc_fee"` // Service fee in percent
 	ToAddress      string         `json:"to_address"`
 	AmountSent     *string        `json:"to_amount"`
 	ToCurrency     CryptoCurrency `json:"to_currency"`
@@ -69,11 +246,107 @@ type OrderResponse struct {
 	SentID         *string        `json:"transaction_id_sent"`
 }
 
+// Date returns when the order was created, or the zero time if the API did
+// not report it.
 func (od *OrderResponse) Date() time.Time {
+	if od.Created == 0 {
+		return time.Time{}
+	}
 	return time.Unix(int64(od.Created), 0)
 }
 
+// DateIn is like Date but returns the time in loc.
+func (od *OrderResponse) DateIn(loc *time.Location) time.Time {
+	if od.Created == 0 {
+		return time.Time{}
+	}
+	return od.Date().In(loc)
+}
+
+// ExpiresAt returns when the order expires, or the zero time if the API did
+// not report an expiry.
+func (od *OrderResponse) ExpiresAt() time.Time {
+	if od.Expires == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(od.Expires), 0)
+}
+
+// TimeRemaining returns the time left until the order expires. It is zero
+// when the order has expired or has no known expiry.
+func (od *OrderResponse) TimeRemaining() time.Duration {
+	if od.Expires == 0 {
+		return 0
+	}
+	if remaining := time.Until(od.ExpiresAt()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Pair returns the exchange direction of the order.
+func (od *OrderResponse) Pair() Pair {
+	return Pair{From: od.FromCurrency, To: od.ToCurrency}
+}
+
+// Summary returns a one-line digest of the order, e.g.
+// "order abc123: 0.01 BTC→ETH state=EXCHANGING rate=15.2".
+func (od *OrderResponse) Summary() string {
+	return od.summary(od.Pair().String())
+}
+
+// summary returns the Summary of the order with pair as its exchange
+// direction.
+func (od *OrderResponse) summary(pair string) string {
+	if od.AmountReceived != nil && *od.AmountReceived != "" {
+		pair = *od.AmountReceived + " " + pair
+	}
+	return fmt.Sprintf("order %s: %s state=%s rate=%s", od.Orderid, pair, od.State, od.Rate)
+}
+
+func (od *OrderResponse) String() string {
+	return od.Summary()
+}
+
+// ReceivedAmount returns the amount deposited so far, or false if nothing has
+// arrived yet.
+func (od *OrderResponse) ReceivedAmount() (*big.Rat, bool) {
+	return parseAmount(od.AmountReceived)
+}
+
+// SentAmount returns the amount paid out, or false if nothing was sent yet.
+func (od *OrderResponse) SentAmount() (*big.Rat, bool) {
+	return parseAmount(od.AmountSent)
+}
+
+// parseAmount parses an optional decimal amount.
+func parseAmount(amount *string) (*big.Rat, bool) {
+	if amount == nil || *amount == "" {
+		return nil, false
+	}
+	return new(big.Rat).SetString(*amount)
+}
+
+// NetworkStatus is the status of a single currency's network.
+type NetworkStatus struct {
+	// Deposit reports whether deposits on the network are processed.
+	Deposit bool `json:"deposit"`
+	// Withdrawal reports whether payouts on the network are processed.
+	Withdrawal bool `json:"withdrawal"`
+}
+
 type ResultResponse struct {
 	Error  string `json:"error"`
 	Result bool   `json:"result"`
+	// DryRun is set when the response was synthesized in dry-run mode.
+	DryRun bool `json:"-"`
+}
+
+// Err returns the error reported by the API as a *ResultError, or nil if
+// there is none.
+func (r *ResultResponse) Err() error {
+	if r == nil || r.Error == "" {
+		return nil
+	}
+	return &ResultError{Code: r.Error}
 }
@@ -0,0 +1,113 @@
+package goexch
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrCertPinMismatch is returned when the server's certificate matches none
+// of the pins set with PinnedCert.
+var ErrCertPinMismatch = errors.New("server certificate does not match the pinned certificate")
+
+// PinnedCert makes the client only accept TLS connections whose certificate
+// chain contains one of the pinned public keys. Each pin is either a PEM
+// encoded certificate or the hex SHA-256 fingerprint of a certificate's
+// SubjectPublicKeyInfo, optionally colon separated. Handshakes with other
+// keys fail with ErrCertPinMismatch. Pinning applies to HTTPS only, so plain
+// HTTP onion endpoints are unaffected; pin their certificate as well when
+// using an HTTPS onion address. It requires the HTTP client to use an
+// *http.Transport, so call it after Client.
+func (c *Client) PinnedCert(pins ...string) error {
+	hashes := make(map[[sha256.Size]byte]bool, len(pins))
+	for _, pin := range pins {
+		hash, err := parsePin(pin)
+		if err != nil {
+			return err
+		}
+		hashes[hash] = true
+	}
+	if len(hashes) == 0 {
+		return errors.New("no certificate pins given")
+	}
+
+	return c.configureTLS(func(config *tls.Config) {
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			for _, cert := range state.PeerCertificates {
+				if hashes[sha256.Sum256(cert.RawSubjectPublicKeyInfo)] {
+					return nil
+				}
+			}
+			return ErrCertPinMismatch
+		}
+	})
+}
+
+// MinTLSVersion makes the client reject TLS versions older than version,
+// e.g. tls.VersionTLS13. Handshakes with servers not supporting it fail with
+// a transport error. Like PinnedCert it configures the *http.Transport of
+// the current HTTP client, so call it after Client; an HTTP client set later
+// keeps its own TLS configuration.
+func (c *Client) MinTLSVersion(version uint16) error {
+	return c.configureTLS(func(config *tls.Config) {
+		config.MinVersion = version
+	})
+}
+
+// configureTLS applies configure to a copy of the TLS configuration of the
+// client's transport. The HTTP client and its transport are copied too, so
+// ones passed to Client are not modified.
+func (c *Client) configureTLS(configure func(*tls.Config)) error {
+	transport, ok := c.client.Transport.(*http.Transport)
+	if !ok {
+		if c.client.Transport != nil {
+			return fmt.Errorf("cannot configure TLS of transport %T", c.client.Transport)
+		}
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	} else {
+		transport = transport.Clone()
+	}
+
+	config := transport.TLSClientConfig
+	if config == nil {
+		config = &tls.Config{}
+	}
+	config = config.Clone()
+	configure(config)
+	transport.TLSClientConfig = config
+
+	client := *c.client
+	client.Transport = transport
+	c.client = &client
+
+	return nil
+}
+
+// parsePin returns the SubjectPublicKeyInfo hash of a PEM certificate or hex
+// fingerprint.
+func parsePin(pin string) ([sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+
+	pin = strings.TrimSpace(pin)
+	if block, _ := pem.Decode([]byte(pin)); block != nil {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return hash, fmt.Errorf("invalid pinned certificate: %w", err)
+		}
+		return sha256.Sum256(cert.RawSubjectPublicKeyInfo), nil
+	}
+
+	raw, err := hex.DecodeString(strings.ReplaceAll(pin, ":", ""))
+	if err != nil || len(raw) != sha256.Size {
+		return hash, fmt.Errorf("invalid certificate pin %q", pin)
+	}
+	copy(hash[:], raw)
+
+	return hash, nil
+}
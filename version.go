@@ -0,0 +1,7 @@
+package goexch
+
+// Version is the version of this package, sent in the default User-Agent.
+const Version = "0.2.0"
+
+// DefaultUserAgent is the User-Agent sent unless overridden with Headers.
+const DefaultUserAgent = "goexch/" + Version
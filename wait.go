@@ -0,0 +1,192 @@
+package goexch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	ErrRateExpired      = errors.New("locked rate expired before a deposit arrived")
+	ErrSlippageExceeded = errors.New("expected output fell below the minimum output")
+	ErrUnderpaid        = errors.New("deposit is below the minimum input")
+)
+
+// WaitForOrder polls the order every pollInterval until it reaches a final
+// phase (PhaseDone or PhaseFailed) or needs user action (PhaseNeedsAction).
+// If ctx is done first, the last fetched order is returned with ctx.Err().
+// A RateFlat order that expires before a deposit arrived fails with
+// ErrRateExpired. An order whose deposit is below MinInput fails with
+// ErrUnderpaid while it awaits or confirms the deposit, instead of waiting for
+// it to be refunded; see OrderResponse.IsUnderpaid.
+// Polls rejected by the rate limiter are skipped rather than failing.
+func (c *Client) WaitForOrder(ctx context.Context, id string, pollInterval time.Duration) (*OrderResponse, error) {
+	return c.poll(ctx, id, pollInterval, func(order *OrderResponse) bool {
+		switch order.Phase() {
+		case PhaseDone, PhaseFailed, PhaseNeedsAction:
+			return true
+		}
+		return false
+	})
+}
+
+// WaitForDeposit is like WaitForOrder but returns as soon as a deposit was
+// detected, i.e. AmountReceived is set or the order left PhaseAwaitingDeposit.
+// Callers should check the state of the returned order, as an expired order
+// also leaves PhaseAwaitingDeposit.
+func (c *Client) WaitForDeposit(ctx context.Context, id string, pollInterval time.Duration) (*OrderResponse, error) {
+	return c.poll(ctx, id, pollInterval, func(order *OrderResponse) bool {
+		return order.AmountReceived != nil || order.Phase() != PhaseAwaitingDeposit
+	})
+}
+
+// WaitForDepositAddress is like WaitForOrder but returns the deposit address
+// as soon as exch.cx has assigned one.
+func (c *Client) WaitForDepositAddress(ctx context.Context, id string, pollInterval time.Duration) (string, error) {
+	order, err := c.poll(ctx, id, pollInterval, func(order *OrderResponse) bool {
+		return depositAddressReady(order) || order.Phase() != PhaseAwaitingDeposit
+	})
+	if err != nil {
+		return "", err
+	}
+	if !depositAddressReady(order) {
+		return "", fmt.Errorf("%w: state is %s", ErrDepositAddressNotReady, order.State)
+	}
+
+	return order.FromAddr, nil
+}
+
+// OrderAndWait creates an order and waits for it like WaitForOrder. The
+// created order is returned even when waiting fails, so a funded order is
+// never lost track of.
+//
+// If opts.MinOutput is set, waiting fails with ErrSlippageExceeded as soon as
+// the deposit is known and the output expected at the order's current rate is
+// below it. exch.cx cannot cancel an order, so the exchange may still go
+// ahead; callers have to decide how to handle it.
+func (c *Client) OrderAndWait(ctx context.Context, from, to CryptoCurrency, address string, opts *OrderOptions, pollInterval time.Duration) (*CreateOrderResposnse, *OrderResponse, error) {
+	created, err := c.OrderContext(ctx, from, to, address, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts == nil || opts.MinOutput == nil {
+		order, err := c.WaitForOrder(ctx, created.OrderID, pollInterval)
+		return created, order, err
+	}
+
+	var slipped bool
+	order, err := c.poll(ctx, created.OrderID, pollInterval, func(order *OrderResponse) bool {
+		if belowMinOutput(order, opts.MinOutput) {
+			slipped = true
+			return true
+		}
+		switch order.Phase() {
+		case PhaseDone, PhaseFailed, PhaseNeedsAction:
+			return true
+		}
+		return false
+	})
+	if err == nil && slipped {
+		err = ErrSlippageExceeded
+	}
+	return created, order, err
+}
+
+// belowMinOutput reports whether the deposit of an order that has not been
+// exchanged yet would yield less than min at its current rate.
+func belowMinOutput(order *OrderResponse, min *big.Rat) bool {
+	if order.State != StateAwaitingInput && order.State != StateConfirmingInput {
+		return false
+	}
+
+	received, ok := order.ReceivedAmount()
+	if !ok {
+		return false
+	}
+
+	estimate, err := (&Rate{Rate: order.Rate, SvcFee: order.SvcFee}).estimate(received)
+	if err != nil {
+		return false
+	}
+	return estimate.Output.Cmp(min) < 0
+}
+
+// rateExpired reports whether a flat-rate order is past its expiry without a
+// deposit, so funding it would no longer get the locked rate.
+func rateExpired(order *OrderResponse) bool {
+	return order.RateMode == RateFlat &&
+		order.AmountReceived == nil &&
+		order.Phase() == PhaseAwaitingDeposit &&
+		order.Expires != 0 &&
+		order.TimeRemaining() == 0
+}
+
+// underpaid reports whether an order that has not been exchanged yet received
+// less than its minimum input.
+func underpaid(order *OrderResponse) bool {
+	if order.State != StateAwaitingInput && order.State != StateConfirmingInput {
+		return false
+	}
+	return order.IsUnderpaid()
+}
+
+// StateCallback sets a function called whenever polling by WaitForOrder,
+// WaitForDeposit, WaitForDepositAddress or OrderAndWait observes a new order
+// state, e.g. to update a progress indicator. before is empty for the first
+// poll. fn is called synchronously from the poll loop, so it delays the next
+// poll and must return promptly; hand slow work off to another goroutine. A
+// nil fn removes the callback.
+func (c *Client) StateCallback(fn func(before, after OrderState, order *OrderResponse)) {
+	c.onState = fn
+}
+
+// poll fetches the order every pollInterval until done reports true.
+func (c *Client) poll(ctx context.Context, id string, pollInterval time.Duration, done func(*OrderResponse) bool) (*OrderResponse, error) {
+	if pollInterval <= 0 {
+		return nil, &ValidationError{Fields: []FieldError{{Field: "pollInterval", Reason: "must be positive"}}}
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var last *OrderResponse
+	for {
+		order, err := c.GetOrderContext(ctx, id)
+		switch {
+		case err == nil:
+			if c.onState != nil && (last == nil || last.State != order.State) {
+				var before OrderState
+				if last != nil {
+					before = last.State
+				}
+				c.onState(before, order.State, order)
+			}
+			last = order
+			if done(order) {
+				return order, nil
+			}
+			if rateExpired(order) {
+				return order, ErrRateExpired
+			}
+			if underpaid(order) {
+				return order, fmt.Errorf("%w: received %s %s, minimum is %s", ErrUnderpaid, *order.AmountReceived, order.FromCurrency, order.MinInput)
+			}
+		case errors.Is(err, RateLimitExceeded):
+			// Try again on the next tick
+		default:
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return last, ctxErr
+			}
+			return last, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}